@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+const (
+	// advicePriorityHigh is assigned to modules that are not instrumented at
+	// all by the installed agent.
+	advicePriorityHigh = "high"
+
+	// advicePriorityMedium is assigned to modules that are instrumented, but
+	// whose installed version is not supported.
+	advicePriorityMedium = "medium"
+
+	// advicePriorityLow is assigned to modules whose installed version is
+	// newer than any tested version, or not within any tested range.
+	advicePriorityLow = "low"
+)
+
+var advicePriorities = []string{advicePriorityHigh, advicePriorityMedium, advicePriorityLow}
+
+// Advisory is the result of comparing the modules installed in an
+// application with the compatibility data and the installed agent.
+type Advisory struct {
+	// InstalledAgent maps the packages that provide instrumentation, i.e.
+	// `newrelic` and `@newrelic/...` packages, to their installed versions.
+	InstalledAgent map[string]string `json:"installedAgent"`
+
+	// Upgrades lists the minimum upgrades of the packages that provide
+	// instrumentation that would instrument every installed module.
+	Upgrades []AgentUpgrade `json:"upgrades"`
+
+	// Advice lists the findings, ordered by priority.
+	Advice []Advice `json:"advice"`
+}
+
+// AgentUpgrade is an upgrade, or installation, of a package that provides
+// instrumentation.
+type AgentUpgrade struct {
+	Package string `json:"package"`
+
+	// CurrentVersion is empty when the package is not installed.
+	CurrentVersion string `json:"currentVersion"`
+	MinimumVersion string `json:"minimumVersion"`
+
+	// Modules lists the installed modules that the upgrade instruments.
+	Modules []string `json:"modules"`
+}
+
+// Advice is a single finding about an installed module.
+type Advice struct {
+	Priority         string `json:"priority"`
+	Name             string `json:"name"`
+	InstalledVersion string `json:"installedVersion"`
+	Reason           string `json:"reason"`
+	Action           string `json:"action"`
+}
+
+// appManifest represents the dependencies of an application `package.json`.
+type appManifest struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// runAdvise writes an upgrade advisory for the application described by the
+// manifest, i.e. a lockfile, an SBOM, or a `package.json`, to the writer.
+// The installed versions of the agent and its instrumentation packages are
+// read from the manifest; `agentVersions` may be used to provide, or
+// override, them.
+func runAdvise(manifestPath string, agentVersions string, data []ReleaseData, format string, writer io.Writer) error {
+	installed, err := readAppPackages(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	agent := installedAgentVersions(installed)
+	if agentVersions != "" {
+		filter, err := parseAgentVersionFilter(agentVersions)
+		if err != nil {
+			return err
+		}
+		for name, version := range filter.versions {
+			agent[name] = version
+		}
+	}
+	if _, found := agent["newrelic"]; found == false {
+		return fmt.Errorf(
+			"could not determine the installed version of newrelic from `%s`, use --agent-version to provide it",
+			manifestPath,
+		)
+	}
+
+	advisory := buildAdvisory(installed, agent, data)
+	switch format {
+	case outputFormatJson:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(advisory)
+	default:
+		renderAdvisory(advisory, writer)
+	}
+	return nil
+}
+
+// readAppPackages reads the packages of an application from a lockfile, an
+// SBOM, or a `package.json`. As a `package.json` only declares ranges, the
+// lowest version allowed by each range is assumed to be installed.
+func readAppPackages(manifestPath string) ([]InstalledPackage, error) {
+	if filepath.Base(manifestPath) != "package.json" {
+		return readInstalledPackages(manifestPath)
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest appManifest
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse `%s`: %w", manifestPath, err)
+	}
+
+	results := make([]InstalledPackage, 0)
+	for _, deps := range []map[string]string{manifest.Dependencies, manifest.DevDependencies, manifest.OptionalDependencies} {
+		for name, versions := range deps {
+			spec := parseDependencySpec(name, versions)
+			if spec.Kind != specKindRange {
+				continue
+			}
+			version := lowestAllowedVersion(spec.Range)
+			if version != "" {
+				results = append(results, InstalledPackage{Name: spec.Package, Version: version})
+			}
+		}
+	}
+	return sortInstalledPackages(results), nil
+}
+
+// lowestAllowedVersion determines the lowest version allowed by a range
+// expression. An empty string is returned when the range cannot be parsed,
+// or has no lower boundary.
+func lowestAllowedVersion(rangeExpression string) string {
+	ranges, err := parseRangeExpression(rangeExpression)
+	if err != nil {
+		return ""
+	}
+
+	lowest := ""
+	for _, r := range ranges {
+		lower := r.GetLowerBoundary()
+		if lower == nil {
+			continue
+		}
+		if lowest == "" || isVersionLower(lower.String(), lowest) == true {
+			lowest = lower.String()
+		}
+	}
+	return lowest
+}
+
+// installedAgentVersions collects the installed versions of the packages
+// that provide instrumentation. When multiple versions of a package are
+// installed, the lowest one is used.
+func installedAgentVersions(installed []InstalledPackage) map[string]string {
+	results := make(map[string]string)
+	for _, pkg := range installed {
+		if pkg.Name != "newrelic" && strings.HasPrefix(pkg.Name, "@newrelic/") == false {
+			continue
+		}
+		current, found := results[pkg.Name]
+		if found == false || isVersionLower(pkg.Version, current) == true {
+			results[pkg.Name] = pkg.Version
+		}
+	}
+	return results
+}
+
+// buildAdvisory compares the installed modules with the compatibility data
+// and the installed versions of the packages that provide instrumentation.
+func buildAdvisory(installed []InstalledPackage, agent map[string]string, data []ReleaseData) Advisory {
+	advisory := Advisory{
+		InstalledAgent: agent,
+		Upgrades:       make([]AgentUpgrade, 0),
+		Advice:         make([]Advice, 0),
+	}
+
+	for _, pkg := range installed {
+		idx := slices.IndexFunc(data, func(info ReleaseData) bool { return info.Name == pkg.Name })
+		if idx < 0 {
+			continue
+		}
+		info := data[idx]
+		advice := Advice{Name: pkg.Name, InstalledVersion: pkg.Version}
+
+		provider, required := splitAgentVersion(info.MinAgentVersion)
+		current, isInstalled := agent[provider]
+		if info.MinAgentVersion != "" && (isInstalled == false || isVersionLower(current, required) == true) {
+			advisory.Upgrades = addAgentUpgrade(advisory.Upgrades, provider, current, required, pkg.Name)
+
+			advice.Priority = advicePriorityHigh
+			if isInstalled == false {
+				advice.Reason = fmt.Sprintf("instrumented by `%s`, which is not installed", provider)
+				advice.Action = fmt.Sprintf("install `%s` %s or later", provider, required)
+			} else {
+				advice.Reason = fmt.Sprintf("instrumented since `%s` %s, but %s is installed", provider, required, current)
+				advice.Action = fmt.Sprintf("upgrade `%s` to %s or later", provider, required)
+			}
+			advisory.Advice = append(advisory.Advice, advice)
+			continue
+		}
+
+		status, details := checkInstalledVersion(pkg.Version, info)
+		switch status {
+		case appStatusTooOld, appStatusIncompatible:
+			advice.Priority = advicePriorityMedium
+			advice.Reason = fmt.Sprintf("%s, %s", status, details)
+			advice.Action = fmt.Sprintf("upgrade `%s` to a supported version", pkg.Name)
+			if info.MinSupportedVersion != "" {
+				advice.Action = fmt.Sprintf("upgrade `%s` to %s or later", pkg.Name, info.MinSupportedVersion)
+			}
+		case appStatusNewer:
+			advice.Priority = advicePriorityLow
+			advice.Reason = fmt.Sprintf("%s, %s", status, details)
+			advice.Action = "verify the instrumentation, or use a tested version"
+		case appStatusUntested:
+			advice.Priority = advicePriorityLow
+			advice.Reason = fmt.Sprintf("%s, %s", status, details)
+			advice.Action = fmt.Sprintf("use a version of `%s` within a tested range", pkg.Name)
+		default:
+			continue
+		}
+		advisory.Advice = append(advisory.Advice, advice)
+	}
+
+	slices.SortStableFunc(advisory.Advice, func(a Advice, b Advice) int {
+		return slices.Index(advicePriorities, a.Priority) - slices.Index(advicePriorities, b.Priority)
+	})
+	slices.SortFunc(advisory.Upgrades, func(a AgentUpgrade, b AgentUpgrade) int {
+		return strings.Compare(a.Package, b.Package)
+	})
+	return advisory
+}
+
+// addAgentUpgrade records that a module requires at least the given version
+// of a package that provides instrumentation.
+func addAgentUpgrade(upgrades []AgentUpgrade, provider string, current string, required string, module string) []AgentUpgrade {
+	idx := slices.IndexFunc(upgrades, func(u AgentUpgrade) bool { return u.Package == provider })
+	if idx < 0 {
+		return append(upgrades, AgentUpgrade{
+			Package:        provider,
+			CurrentVersion: current,
+			MinimumVersion: required,
+			Modules:        []string{module},
+		})
+	}
+
+	if isVersionLower(upgrades[idx].MinimumVersion, required) == true {
+		upgrades[idx].MinimumVersion = required
+	}
+	upgrades[idx].Modules = appendUnique(upgrades[idx].Modules, module)
+	return upgrades
+}
+
+// renderAdvisory renders the advisory as a Markdown document.
+func renderAdvisory(advisory Advisory, writer io.Writer) {
+	names := make([]string, 0, len(advisory.InstalledAgent))
+	for name := range advisory.InstalledAgent {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	installed := make([]string, 0, len(names))
+	for _, name := range names {
+		installed = append(installed, fmt.Sprintf("`%s` %s", name, advisory.InstalledAgent[name]))
+	}
+
+	io.WriteString(writer, "# Upgrade advisory\n\n")
+	io.WriteString(writer, fmt.Sprintf("Installed: %s.\n", strings.Join(installed, ", ")))
+
+	if len(advisory.Advice) == 0 {
+		io.WriteString(writer, "\nEvery installed module is instrumented and supported.\n")
+		return
+	}
+
+	if len(advisory.Upgrades) > 0 {
+		upgradesTable := table.NewWriter()
+		upgradesTable.AppendHeader(table.Row{"Package name", "Installed version", "Minimum version", "Instruments"})
+		for _, upgrade := range advisory.Upgrades {
+			current := upgrade.CurrentVersion
+			if current == "" {
+				current = "not installed"
+			}
+			modules := make([]string, 0, len(upgrade.Modules))
+			for _, module := range upgrade.Modules {
+				modules = append(modules, fmt.Sprintf("`%s`", module))
+			}
+			upgradesTable.AppendRow(table.Row{
+				fmt.Sprintf("`%s`", upgrade.Package),
+				current,
+				upgrade.MinimumVersion,
+				strings.Join(modules, ", "),
+			})
+		}
+
+		io.WriteString(writer, "\n## Recommended upgrades\n\n")
+		io.WriteString(
+			writer,
+			heredoc.Doc(`
+				The following upgrades are the minimum required to instrument every
+				installed module that is not currently instrumented.
+			`),
+		)
+		io.WriteString(writer, "\n")
+		io.WriteString(writer, upgradesTable.RenderMarkdown())
+		io.WriteString(writer, "\n")
+	}
+
+	adviceTable := table.NewWriter()
+	adviceTable.AppendHeader(table.Row{"Priority", "Package name", "Installed version", "Reason", "Action"})
+	for _, advice := range advisory.Advice {
+		adviceTable.AppendRow(table.Row{
+			advice.Priority,
+			fmt.Sprintf("`%s`", advice.Name),
+			advice.InstalledVersion,
+			advice.Reason,
+			advice.Action,
+		})
+	}
+	io.WriteString(writer, "\n## Findings\n\n")
+	io.WriteString(writer, adviceTable.RenderMarkdown())
+	io.WriteString(writer, "\n")
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readAppPackages(t *testing.T) {
+	t.Run("reads a package.json", func(t *testing.T) {
+		found, err := readAppPackages("testdata/advise/package.json")
+		require.Nil(t, err)
+		assert.Equal(t, []InstalledPackage{
+			{Name: "@scope/pkg", Version: "3.0.0"},
+			{Name: "express", Version: "4.18.2"},
+			{Name: "koa", Version: "2.14.0"},
+			{Name: "lodash", Version: "4.17.0"},
+			{Name: "newrelic", Version: "3.0.0"},
+		}, found)
+	})
+
+	t.Run("reads a lockfile", func(t *testing.T) {
+		found, err := readAppPackages("testdata/lockfiles/pnpm-v9/pnpm-lock.yaml")
+		require.Nil(t, err)
+		assert.Len(t, found, 2)
+	})
+}
+
+func Test_buildAdvisory(t *testing.T) {
+	data, err := readReleaseDataFile("testdata/check-app-report.json")
+	require.Nil(t, err)
+	installed, err := readAppPackages("testdata/advise/package.json")
+	require.Nil(t, err)
+
+	advisory := buildAdvisory(installed, installedAgentVersions(installed), data)
+	assert.Equal(t, map[string]string{"newrelic": "3.0.0"}, advisory.InstalledAgent)
+	assert.Equal(t, []AgentUpgrade{
+		{
+			Package:        "@newrelic/scope-plugin",
+			CurrentVersion: "",
+			MinimumVersion: "1.0.0",
+			Modules:        []string{"@scope/pkg"},
+		},
+		{
+			Package:        "newrelic",
+			CurrentVersion: "3.0.0",
+			MinimumVersion: "3.2.0",
+			Modules:        []string{"koa"},
+		},
+	}, advisory.Upgrades)
+
+	require.Len(t, advisory.Advice, 3)
+	assert.Equal(t, Advice{
+		Priority:         advicePriorityHigh,
+		Name:             "@scope/pkg",
+		InstalledVersion: "3.0.0",
+		Reason:           "instrumented by `@newrelic/scope-plugin`, which is not installed",
+		Action:           "install `@newrelic/scope-plugin` 1.0.0 or later",
+	}, advisory.Advice[0])
+	assert.Equal(t, advicePriorityHigh, advisory.Advice[1].Priority)
+	assert.Equal(t, "koa", advisory.Advice[1].Name)
+	assert.Equal(t, "upgrade `newrelic` to 3.2.0 or later", advisory.Advice[1].Action)
+	assert.Equal(t, advicePriorityMedium, advisory.Advice[2].Priority)
+	assert.Equal(t, "express", advisory.Advice[2].Name)
+	assert.Equal(t, "upgrade `express` to 4.6.0 or later", advisory.Advice[2].Action)
+}
+
+func Test_buildAdvisory_untested(t *testing.T) {
+	data, err := readReleaseDataFile("testdata/check-app-gap-report.json")
+	require.Nil(t, err)
+	installed := []InstalledPackage{{Name: "mongodb", Version: "3.1.0"}}
+
+	advisory := buildAdvisory(installed, map[string]string{"newrelic": "12.0.0"}, data)
+	assert.Empty(t, advisory.Upgrades)
+	assert.Equal(t, []Advice{{
+		Priority:         advicePriorityLow,
+		Name:             "mongodb",
+		InstalledVersion: "3.1.0",
+		Reason:           "untested, not within a tested range: `>=2 <3`, `>=4`",
+		Action:           "use a version of `mongodb` within a tested range",
+	}}, advisory.Advice)
+
+	installed[0].Version = "4.1.0"
+	advisory = buildAdvisory(installed, map[string]string{"newrelic": "12.0.0"}, data)
+	assert.Empty(t, advisory.Advice)
+}
+
+func Test_addAgentUpgrade(t *testing.T) {
+	upgrades := addAgentUpgrade(nil, "newrelic", "3.0.0", "4.0.0", "a")
+	upgrades = addAgentUpgrade(upgrades, "newrelic", "3.0.0", "5.0.0", "b")
+	upgrades = addAgentUpgrade(upgrades, "newrelic", "3.0.0", "3.5.0", "c")
+	assert.Equal(t, []AgentUpgrade{{
+		Package:        "newrelic",
+		CurrentVersion: "3.0.0",
+		MinimumVersion: "5.0.0",
+		Modules:        []string{"a", "b", "c"},
+	}}, upgrades)
+}
+
+func Test_runAdvise(t *testing.T) {
+	data, err := readReleaseDataFile("testdata/check-app-report.json")
+	require.Nil(t, err)
+
+	t.Run("renders markdown", func(t *testing.T) {
+		builder := &strings.Builder{}
+		err := runAdvise("testdata/advise/package.json", "", data, outputFormatMarkdown, builder)
+		require.Nil(t, err)
+
+		found := builder.String()
+		assert.Contains(t, found, "Installed: `newrelic` 3.0.0.")
+		assert.Contains(t, found, "| `@newrelic/scope-plugin` | not installed | 1.0.0 | `@scope/pkg` |")
+		assert.Contains(t, found, "| `newrelic` | 3.0.0 | 3.2.0 | `koa` |")
+		assert.Contains(t, found, "| medium | `express` | 4.18.2 | known incompatible, `4.18.2` is not supported: Breaks the router. |")
+	})
+
+	t.Run("agent versions override the manifest", func(t *testing.T) {
+		builder := &strings.Builder{}
+		err := runAdvise(
+			"testdata/advise/package.json",
+			"3.2.0,@newrelic/scope-plugin@1.0.0",
+			data,
+			outputFormatJson,
+			builder,
+		)
+		require.Nil(t, err)
+		assert.Contains(t, builder.String(), `"upgrades": []`)
+		assert.Contains(t, builder.String(), `"priority": "low"`)
+	})
+
+	t.Run("requires the agent version", func(t *testing.T) {
+		err := runAdvise("testdata/lockfiles/pnpm-v9/pnpm-lock.yaml", "", data, outputFormatMarkdown, &strings.Builder{})
+		assert.ErrorContains(t, err, "could not determine the installed version of newrelic")
+	})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"blitznote.com/src/semver/v3"
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// agentVersionFilter describes the installed versions of the agent, and of
+// any separately installed instrumentation packages, by which the data is
+// filtered in order to produce a report as of a specific agent release.
+type agentVersionFilter struct {
+	// versions maps the name of the package that provides instrumentation,
+	// i.e. `newrelic` or a `@newrelic/...` package, to its installed version.
+	versions map[string]string
+}
+
+// parseAgentVersionFilter parses a comma separated list of versions, e.g.
+// `11.5.0,@newrelic/apollo-server-plugin@3.0.0`. A plain version is the
+// version of the `newrelic` package, and must be present.
+func parseAgentVersionFilter(input string) (agentVersionFilter, error) {
+	filter := agentVersionFilter{versions: make(map[string]string)}
+	for _, item := range splitList(input) {
+		name, version := splitAgentVersion(item)
+		_, err := semver.NewVersion([]byte(version))
+		if err != nil {
+			return filter, fmt.Errorf("invalid agent version `%s`: %w", item, err)
+		}
+		filter.versions[name] = version
+	}
+
+	if _, found := filter.versions["newrelic"]; found == false {
+		return filter, fmt.Errorf("invalid agent version `%s`: missing the version of newrelic", input)
+	}
+	return filter, nil
+}
+
+// String renders the filter in the same form that it is parsed from, with
+// the `newrelic` version first.
+func (avf agentVersionFilter) String() string {
+	names := make([]string, 0, len(avf.versions))
+	for name := range avf.versions {
+		if name != "newrelic" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	items := []string{avf.versions["newrelic"]}
+	for _, name := range names {
+		items = append(items, name+"@"+avf.versions[name])
+	}
+	return strings.Join(items, ", ")
+}
+
+// isInstrumented determines if a module is instrumented by the filtered
+// versions. Modules instrumented by a separately installed package are only
+// considered to not be instrumented when a version of that package is part
+// of the filter, because such packages are released independently of the
+// agent. Modules without a valid minimum agent version are always
+// instrumented.
+func (avf agentVersionFilter) isInstrumented(info ReleaseData) bool {
+	name, required := splitAgentVersion(info.MinAgentVersion)
+	_, err := semver.NewVersion([]byte(required))
+	if err != nil {
+		return true
+	}
+
+	installed, found := avf.versions[name]
+	if found == false {
+		return true
+	}
+	return isVersionLower(installed, required) == false
+}
+
+// filterByAgentVersion splits the data into the modules that are
+// instrumented by the filtered versions, and the modules that are not.
+func filterByAgentVersion(data []ReleaseData, filter agentVersionFilter) ([]ReleaseData, []ReleaseData) {
+	instrumented := make([]ReleaseData, 0, len(data))
+	excluded := make([]ReleaseData, 0)
+	for _, info := range data {
+		if filter.isInstrumented(info) == true {
+			instrumented = append(instrumented, info)
+		} else {
+			excluded = append(excluded, info)
+		}
+	}
+	return instrumented, excluded
+}
+
+// renderAgentVersionNote renders a Markdown section that states which
+// release the report reflects, and lists the modules that are only
+// instrumented by later releases.
+func renderAgentVersionNote(filter agentVersionFilter, excluded []ReleaseData, writer io.Writer) {
+	io.WriteString(writer, "## Modules instrumented by later releases\n\n")
+	io.WriteString(
+		writer,
+		heredoc.Docf(`
+			The compatibility table reflects the modules instrumented as of %snewrelic%s
+			%s. Modules instrumented by a separately installed package are included
+			unless a version of that package is given.
+		`, "`", "`", filter.String()),
+	)
+	if len(excluded) == 0 {
+		return
+	}
+
+	outputTable := table.NewWriter()
+	outputTable.AppendHeader(table.Row{"Package name", "Introduced in"})
+	for _, info := range excluded {
+		minAgentVersion := info.MinAgentVersion
+		if strings.HasPrefix(minAgentVersion, "@") == true {
+			minAgentVersion = fmt.Sprintf("`%s`", minAgentVersion)
+		}
+		outputTable.AppendRow(table.Row{fmt.Sprintf("`%s`", info.Name), minAgentVersion})
+	}
+	io.WriteString(writer, "\n")
+	io.WriteString(writer, outputTable.RenderMarkdown())
+	io.WriteString(writer, "\n")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseAgentVersionFilter(t *testing.T) {
+	t.Run("parses agent and plugin versions", func(t *testing.T) {
+		filter, err := parseAgentVersionFilter("@newrelic/foo@2.0.0, 11.5.0")
+		require.Nil(t, err)
+		assert.Equal(t, map[string]string{"newrelic": "11.5.0", "@newrelic/foo": "2.0.0"}, filter.versions)
+		assert.Equal(t, "11.5.0, @newrelic/foo@2.0.0", filter.String())
+	})
+
+	t.Run("requires the agent version", func(t *testing.T) {
+		_, err := parseAgentVersionFilter("@newrelic/foo@2.0.0")
+		assert.ErrorContains(t, err, "missing the version of newrelic")
+	})
+
+	t.Run("rejects invalid versions", func(t *testing.T) {
+		_, err := parseAgentVersionFilter("eleven")
+		assert.ErrorContains(t, err, "invalid agent version `eleven`")
+	})
+}
+
+func Test_filterByAgentVersion(t *testing.T) {
+	data := []ReleaseData{
+		{Name: "a", MinAgentVersion: "2.0.0"},
+		{Name: "b", MinAgentVersion: "11.5.0"},
+		{Name: "c", MinAgentVersion: "12.0.0"},
+		{Name: "d", MinAgentVersion: "@newrelic/foo@3.0.0"},
+		{Name: "e", MinAgentVersion: "@newrelic/bar@3.0.0"},
+		{Name: "f", MinAgentVersion: ""},
+	}
+
+	filter, err := parseAgentVersionFilter("11.5.0,@newrelic/foo@2.0.0")
+	require.Nil(t, err)
+	instrumented, excluded := filterByAgentVersion(data, filter)
+
+	names := func(data []ReleaseData) []string {
+		results := make([]string, 0)
+		for _, info := range data {
+			results = append(results, info.Name)
+		}
+		return results
+	}
+	assert.Equal(t, []string{"a", "b", "e", "f"}, names(instrumented))
+	assert.Equal(t, []string{"c", "d"}, names(excluded))
+}
+
+func Test_renderAgentVersionNote(t *testing.T) {
+	filter, err := parseAgentVersionFilter("11.5.0")
+	require.Nil(t, err)
+
+	builder := &strings.Builder{}
+	renderAgentVersionNote(filter, []ReleaseData{
+		{Name: "c", MinAgentVersion: "12.0.0"},
+		{Name: "d", MinAgentVersion: "@newrelic/foo@3.0.0"},
+	}, builder)
+
+	found := builder.String()
+	assert.Contains(t, found, "## Modules instrumented by later releases")
+	assert.Contains(t, found, "as of `newrelic`\n11.5.0.")
+	assert.Contains(t, found, "| `c` | 12.0.0 |")
+	assert.Contains(t, found, "| `d` | `@newrelic/foo@3.0.0` |")
+
+	builder.Reset()
+	renderAgentVersionNote(filter, []ReleaseData{}, builder)
+	assert.NotContains(t, builder.String(), "| Package name |")
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrAiCompatInvalid = errors.New("invalid ai compatibility descriptor")
+
+// AiCompatProblem describes a single problem found within the AI
+// compatibility JSON descriptor document.
+type AiCompatProblem struct {
+	// Pointer is the JSON pointer (RFC 6901) of the value that has the
+	// problem.
+	Pointer string
+
+	// Line is the 1-based line number of the value within the document. A
+	// value of `0` indicates the line could not be determined.
+	Line int
+
+	Message string
+}
+
+func (acp AiCompatProblem) String() string {
+	if acp.Line == 0 {
+		return fmt.Sprintf("%s: %s", acp.Pointer, acp.Message)
+	}
+	return fmt.Sprintf("%s (line %d): %s", acp.Pointer, acp.Line, acp.Message)
+}
+
+// aiCompatValidate verifies that the parsed descriptor is complete enough to
+// be rendered. The raw data of the document is used to locate the problems
+// within the document. An error wrapping [ErrAiCompatInvalid] that lists every
+// problem is returned if any problems were found.
+func aiCompatValidate(data []byte, input AiCompatJson) error {
+	problems := aiCompatFindProblems(input)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	positions, _ := jsonPositions(data)
+	lines := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		problem.Line = lineForPointer(data, positions, problem.Pointer)
+		lines = append(lines, problem.String())
+	}
+	return fmt.Errorf("%w:\n  %s", ErrAiCompatInvalid, strings.Join(lines, "\n  "))
+}
+
+// aiCompatFindProblems checks every envelope in the descriptor for a known
+// kind and for the fields required by that kind.
+func aiCompatFindProblems(input AiCompatJson) []AiCompatProblem {
+	results := make([]AiCompatProblem, 0)
+	report := func(pointer string, format string, args ...any) {
+		results = append(results, AiCompatProblem{
+			Pointer: pointer,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	seenTitles := make(map[string]bool)
+	for i, envelope := range input {
+		pointer := fmt.Sprintf("/%d", i)
+
+		if envelope.Title == "" {
+			report(pointer, "entry is missing a title")
+		} else if seenTitles[envelope.Title] == true {
+			report(pointer+"/title", "duplicate title `%s`", envelope.Title)
+		}
+		seenTitles[envelope.Title] = true
+
+		switch strings.ToLower(envelope.Kind) {
+		case AiCompatKindGateway:
+			results = append(results, aiCompatCheckModels(pointer, envelope.Models)...)
+		case AiCompatKindAbstraction:
+			results = append(results, aiCompatCheckFeatures(pointer+"/features", envelope.Features, nil)...)
+			results = append(results, aiCompatCheckProviders(pointer, envelope.Providers)...)
+		case AiCompatKindSdk:
+			results = append(results, aiCompatCheckFeatures(pointer+"/features", envelope.Features, nil)...)
+		case "":
+			report(pointer, "entry is missing a kind")
+		default:
+			report(
+				pointer+"/kind",
+				"unknown kind `%s`, expected one of `%s`, `%s`, or `%s`",
+				envelope.Kind,
+				AiCompatKindGateway,
+				AiCompatKindAbstraction,
+				AiCompatKindSdk,
+			)
+		}
+	}
+
+	return results
+}
+
+// aiCompatCheckModels validates the set of models behind a gateway. Feature
+// titles must be spelled identically across all of the models, otherwise
+// they would be rendered as distinct columns. A model may leave out features
+// listed by other models, which are rendered as "-".
+func aiCompatCheckModels(pointer string, models []AiCompatModel) []AiCompatProblem {
+	results := make([]AiCompatProblem, 0)
+	if len(models) == 0 {
+		return append(results, AiCompatProblem{
+			Pointer: pointer + "/models",
+			Message: "gateway must define at least one model",
+		})
+	}
+
+	seenNames := make(map[string]bool)
+	featureTitles := make(map[string]string)
+	for i, model := range models {
+		modelPointer := fmt.Sprintf("%s/models/%d", pointer, i)
+		if model.Name == "" {
+			results = append(results, AiCompatProblem{
+				Pointer: modelPointer,
+				Message: "model is missing a name",
+			})
+		} else if seenNames[model.Name] == true {
+			results = append(results, AiCompatProblem{
+				Pointer: modelPointer + "/name",
+				Message: fmt.Sprintf("duplicate model `%s`", model.Name),
+			})
+		}
+		seenNames[model.Name] = true
+
+		results = append(
+			results,
+			aiCompatCheckFeatures(modelPointer+"/features", model.Features, featureTitles)...,
+		)
+	}
+
+	return results
+}
+
+// aiCompatCheckFeatures validates a set of features. When `knownTitles` is
+// not `nil`, it maps normalized titles to the spelling first seen for them,
+// and is used to detect titles that differ only in case or spacing from a
+// title used by a sibling set of features.
+func aiCompatCheckFeatures(
+	pointer string,
+	features []AiCompatFeature,
+	knownTitles map[string]string,
+) []AiCompatProblem {
+	results := make([]AiCompatProblem, 0)
+	if len(features) == 0 {
+		return append(results, AiCompatProblem{
+			Pointer: pointer,
+			Message: "at least one feature must be defined",
+		})
+	}
+
+	seenTitles := make(map[string]bool)
+	for i, feature := range features {
+		featurePointer := fmt.Sprintf("%s/%d", pointer, i)
+		if feature.Title == "" {
+			results = append(results, AiCompatProblem{
+				Pointer: featurePointer,
+				Message: "feature is missing a title",
+			})
+			continue
+		}
+		if seenTitles[feature.Title] == true {
+			results = append(results, AiCompatProblem{
+				Pointer: featurePointer + "/title",
+				Message: fmt.Sprintf("duplicate feature `%s`", feature.Title),
+			})
+		}
+		seenTitles[feature.Title] = true
+
+		if knownTitles == nil {
+			continue
+		}
+		normalized := strings.ToLower(strings.Join(strings.Fields(feature.Title), " "))
+		known, found := knownTitles[normalized]
+		if found == false {
+			knownTitles[normalized] = feature.Title
+		} else if known != feature.Title {
+			results = append(results, AiCompatProblem{
+				Pointer: featurePointer + "/title",
+				Message: fmt.Sprintf(
+					"feature `%s` is inconsistent with feature `%s` of another model",
+					feature.Title,
+					known,
+				),
+			})
+		}
+	}
+
+	return results
+}
+
+// aiCompatCheckProviders validates the set of providers of an abstraction.
+func aiCompatCheckProviders(pointer string, providers []AiCompatProvider) []AiCompatProblem {
+	results := make([]AiCompatProblem, 0)
+	if len(providers) == 0 {
+		return append(results, AiCompatProblem{
+			Pointer: pointer + "/providers",
+			Message: "abstraction must define at least one provider",
+		})
+	}
+
+	seenNames := make(map[string]bool)
+	for i, provider := range providers {
+		providerPointer := fmt.Sprintf("%s/providers/%d", pointer, i)
+		if provider.Name == "" {
+			results = append(results, AiCompatProblem{
+				Pointer: providerPointer,
+				Message: "provider is missing a name",
+			})
+		} else if seenNames[provider.Name] == true {
+			results = append(results, AiCompatProblem{
+				Pointer: providerPointer + "/name",
+				Message: fmt.Sprintf("duplicate provider `%s`", provider.Name),
+			})
+		}
+		seenNames[provider.Name] = true
+	}
+
+	return results
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_aiCompatValidate(t *testing.T) {
+	t.Run("accepts a valid descriptor", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/ai-compat.json")
+		require.Nil(t, err)
+		parsed, err := aiCompatReadJson(bytes.NewReader(data))
+		require.Nil(t, err)
+
+		err = aiCompatValidate(data, parsed)
+		assert.Nil(t, err)
+	})
+
+	t.Run("accepts models that leave out features of other models", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/ai-compat-partial-features.json")
+		require.Nil(t, err)
+		parsed, err := aiCompatReadJson(bytes.NewReader(data))
+		require.Nil(t, err)
+
+		err = aiCompatValidate(data, parsed)
+		assert.Nil(t, err)
+	})
+
+	t.Run("reports located problems", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/ai-compat-invalid.json")
+		require.Nil(t, err)
+		parsed, err := aiCompatReadJson(bytes.NewReader(data))
+		require.Nil(t, err)
+
+		err = aiCompatValidate(data, parsed)
+		assert.ErrorIs(t, err, ErrAiCompatInvalid)
+
+		expected := []string{
+			"/0/models (line 5): gateway must define at least one model",
+			"/1/models/0/features/1/title (line 15): duplicate feature `Text`",
+			"/1/models/1/name (line 19): duplicate model `Foo`",
+			"/1/models/1/features/0/title (line 21): feature `text` is inconsistent with feature `Text` of another model",
+			"/2/title (line 28): duplicate title `Mixed Gateway`",
+			"/2/features (line 29): at least one feature must be defined",
+			"/2/providers/0 (line 31): provider is missing a name",
+			"/3/kind (line 35): unknown kind `widget`, expected one of `gateway`, `abstraction`, or `sdk`",
+			"/4 (line 41): entry is missing a kind",
+		}
+		for _, line := range expected {
+			assert.Contains(t, err.Error(), line)
+		}
+	})
+}
+
+func Test_aiCompatFindProblems(t *testing.T) {
+	t.Run("requires providers for abstractions", func(t *testing.T) {
+		input := AiCompatJson{
+			{
+				Kind:     AiCompatKindAbstraction,
+				Title:    "foo",
+				Features: []AiCompatFeature{{Title: "one"}},
+			},
+		}
+		expected := []AiCompatProblem{
+			{Pointer: "/0/providers", Message: "abstraction must define at least one provider"},
+		}
+		assert.Equal(t, expected, aiCompatFindProblems(input))
+	})
+
+	t.Run("accepts differing kind case", func(t *testing.T) {
+		input := AiCompatJson{
+			{Kind: "SDK", Title: "foo", Features: []AiCompatFeature{{Title: "one"}}},
+		}
+		assert.Empty(t, aiCompatFindProblems(input))
+	})
+
+	t.Run("reports features missing titles", func(t *testing.T) {
+		input := AiCompatJson{
+			{Kind: AiCompatKindSdk, Title: "foo", Features: []AiCompatFeature{{Supported: true}}},
+		}
+		expected := []AiCompatProblem{
+			{Pointer: "/0/features/0", Message: "feature is missing a title"},
+		}
+		assert.Equal(t, expected, aiCompatFindProblems(input))
+	})
+}
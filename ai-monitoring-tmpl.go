@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	_ "embed"
 	"encoding/json"
 	"fmt"
@@ -22,11 +23,21 @@ func RenderAiCompatDoc(descriptorJsonFile string, writer io.Writer) error {
 		return fmt.Errorf("could not read descriptor json file: %w", err)
 	}
 
-	parsedJson, err := aiCompatReadJson(reader)
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("could not read descriptor json file: %w", err)
+	}
+
+	parsedJson, err := aiCompatReadJson(bytes.NewReader(data))
 	if err != nil {
 		return fmt.Errorf("could not parse descriptor json file: %w", err)
 	}
 
+	err = aiCompatValidate(data, parsedJson)
+	if err != nil {
+		return err
+	}
+
 	tmpl, err := aiCompatLoadTemplate()
 	if err != nil {
 		return fmt.Errorf("could not load template: %w", err)
@@ -140,12 +151,20 @@ func aiCompatBoolEmoji(input bool) string {
 
 // aiModelsToTable renders a set of gateway objects into a Markdown table.
 // It is added to the AI Monitoring template as a convenience function.
+// The columns of the table are the union of the features of all models.
 func aiModelsToTable(input []AiCompatModel) string {
 	result := strings.Builder{}
+	if len(input) == 0 {
+		return ""
+	}
 
 	featureTitles := make([]string, 0)
-	for _, val := range input[0].Features {
-		featureTitles = append(featureTitles, val.Title)
+	for _, model := range input {
+		for _, val := range model.Features {
+			if slices.Contains(featureTitles, val.Title) == false {
+				featureTitles = append(featureTitles, val.Title)
+			}
+		}
 	}
 	slices.Sort(featureTitles)
 	featureTitles = append([]string{"Model"}, featureTitles...)
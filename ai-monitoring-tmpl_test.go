@@ -42,6 +42,11 @@ func Test_RenderAiCompatDoc(t *testing.T) {
 		assert.ErrorContains(t, err, "could not parse descriptor json file")
 	})
 
+	t.Run("errors if descriptor is invalid", func(t *testing.T) {
+		err := RenderAiCompatDoc("testdata/ai-compat-invalid.json", io.Discard)
+		assert.ErrorIs(t, err, ErrAiCompatInvalid)
+	})
+
 	t.Run("errors if template is invalid", func(t *testing.T) {
 		curTmplString := aiMonitoringTmplString
 		t.Cleanup(func() {
@@ -64,3 +69,23 @@ func Test_RenderAiCompatDoc(t *testing.T) {
 		assert.ErrorContains(t, err, "failed to render template")
 	})
 }
+
+func Test_aiModelsToTable(t *testing.T) {
+	t.Run("renders nothing for no models", func(t *testing.T) {
+		assert.Equal(t, "", aiModelsToTable([]AiCompatModel{}))
+	})
+
+	t.Run("includes features of every model", func(t *testing.T) {
+		input := []AiCompatModel{
+			{Name: "foo", Features: []AiCompatFeature{{Title: "One", Supported: true}}},
+			{Name: "bar", Features: []AiCompatFeature{{Title: "Two", Supported: false}}},
+		}
+		expected := strings.Join([]string{
+			"| Model | One | Two |",
+			"| --- | --- | --- |",
+			"| bar | - | ❌ |",
+			"| foo | ✅ | - |",
+		}, "\n")
+		assert.Equal(t, expected, aiModelsToTable(input))
+	})
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"blitznote.com/src/semver/v3"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+const (
+	// appStatusSupported indicates the installed version is within the
+	// supported range of the module.
+	appStatusSupported = "supported"
+
+	// appStatusTooOld indicates the installed version is lower than the
+	// minimum supported version of the module.
+	appStatusTooOld = "too old"
+
+	// appStatusNewer indicates the installed version is higher than any
+	// version of the module that has been tested.
+	appStatusNewer = "newer than tested"
+
+	// appStatusUntested indicates the installed version is within the
+	// supported versions of the module, but is not within any range covered
+	// by its versioned tests, e.g. a major version that was skipped.
+	appStatusUntested = "untested"
+
+	// appStatusIncompatible indicates the installed version is within a range
+	// of the module that is known to be incompatible.
+	appStatusIncompatible = "known incompatible"
+)
+
+var ErrUnsupportedDependencies = errors.New("unsupported dependencies found")
+
+// AppDependencyStatus describes the support for an instrumented module that
+// is installed in an application.
+type AppDependencyStatus struct {
+	Name             string `json:"name"`
+	InstalledVersion string `json:"installedVersion"`
+	Status           string `json:"status"`
+
+	// Details explains the status, e.g. the minimum supported version when
+	// the installed version is too old.
+	Details string `json:"details"`
+}
+
+// runCheckApp reads the lockfile, or SBOM, of an application and writes the
+// support status of every instrumented module installed in the application
+// to the writer. An error wrapping [ErrUnsupportedDependencies] is returned if
+// any installed module is too old or known to be incompatible.
+func runCheckApp(lockfilePath string, data []ReleaseData, format string, writer io.Writer) error {
+	installed, err := readInstalledPackages(lockfilePath)
+	if err != nil {
+		return err
+	}
+
+	statuses := checkAppDependencies(installed, data)
+	switch format {
+	case outputFormatJson:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(statuses)
+		if err != nil {
+			return err
+		}
+	default:
+		renderAppStatuses(statuses, writer)
+	}
+
+	unsupported := 0
+	for _, status := range statuses {
+		if status.Status == appStatusTooOld || status.Status == appStatusIncompatible {
+			unsupported += 1
+		}
+	}
+	if unsupported > 0 {
+		return fmt.Errorf("%w: %d dependency(ies)", ErrUnsupportedDependencies, unsupported)
+	}
+	return nil
+}
+
+// readReleaseDataFile reads the data of a report previously generated with
+// the JSON output format.
+func readReleaseDataFile(reportPath string) ([]ReleaseData, error) {
+	file, err := os.Open(reportPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report: %w", err)
+	}
+	defer file.Close()
+
+	var data []ReleaseData
+	err = json.NewDecoder(file).Decode(&data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report `%s`: %w", reportPath, err)
+	}
+	return data, nil
+}
+
+// checkAppDependencies determines the support status of every installed
+// package that is an instrumented module. Installed packages that are not
+// instrumented are omitted from the result.
+func checkAppDependencies(installed []InstalledPackage, data []ReleaseData) []AppDependencyStatus {
+	results := make([]AppDependencyStatus, 0)
+	for _, pkg := range installed {
+		idx := slices.IndexFunc(data, func(info ReleaseData) bool { return info.Name == pkg.Name })
+		if idx < 0 {
+			continue
+		}
+		status, details := checkInstalledVersion(pkg.Version, data[idx])
+		results = append(results, AppDependencyStatus{
+			Name:             pkg.Name,
+			InstalledVersion: pkg.Version,
+			Status:           status,
+			Details:          details,
+		})
+	}
+	return results
+}
+
+// checkInstalledVersion determines the support status of a version of a
+// module, along with an explanation of the status.
+func checkInstalledVersion(version string, info ReleaseData) (string, string) {
+	for _, incompatible := range info.KnownIncompatible {
+		if versionInRange(version, incompatible.Versions) == true {
+			details := fmt.Sprintf("`%s` is not supported", incompatible.Versions)
+			if incompatible.Comment != "" {
+				details = fmt.Sprintf("%s: %s", details, incompatible.Comment)
+			}
+			return appStatusIncompatible, details
+		}
+	}
+
+	if isVersionLower(version, info.MinSupportedVersion) == true {
+		return appStatusTooOld, fmt.Sprintf("minimum supported version is %s", info.MinSupportedVersion)
+	}
+
+	testedUpTo := testedUpToVersion(info)
+	if testedUpTo != "" && isVersionLower(testedUpTo, version) == true {
+		return appStatusNewer, fmt.Sprintf("tested up to %s", testedUpTo)
+	}
+
+	if len(info.Tests) > 0 && versionTested(version, info) == false {
+		return appStatusUntested, fmt.Sprintf("not within a tested range: %s", testedRanges(info))
+	}
+
+	if info.MinAgentVersion == "" {
+		return appStatusSupported, ""
+	}
+	return appStatusSupported, fmt.Sprintf("instrumented since %s", info.MinAgentVersion)
+}
+
+// testedUpToVersion determines the highest version of a module that is known
+// to have been tested. That is the highest version selected by the versioned
+// test runner, or the latest published version when the tested versions are
+// not known.
+func testedUpToVersion(info ReleaseData) string {
+	if len(info.TestedVersions) == 0 {
+		return info.LatestVersion
+	}
+	versions := sortVersionStrings(slices.Clone(info.TestedVersions))
+	return versions[len(versions)-1]
+}
+
+// versionTested determines if a version is within a range covered by the
+// versioned tests of a module. A range of `latest` only covers the latest
+// published version.
+func versionTested(version string, info ReleaseData) bool {
+	return slices.ContainsFunc(info.Tests, func(test TargetTest) bool {
+		if normalizeRangeString(test.Versions) == max_range {
+			return version == info.LatestVersion
+		}
+		return versionInRange(version, test.Versions)
+	})
+}
+
+// testedRanges lists the distinct ranges covered by the versioned tests of a
+// module, e.g. "`>=2 <3`, `>=4`".
+func testedRanges(info ReleaseData) string {
+	ranges := make([]string, 0, len(info.Tests))
+	for _, test := range info.Tests {
+		ranges = appendUnique(ranges, fmt.Sprintf("`%s`", strings.TrimSpace(test.Versions)))
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// versionInRange determines if a version satisfies a range expression, which
+// may include `||` separated ranges. Versions and ranges that cannot be
+// parsed never match.
+func versionInRange(version string, rangeExpression string) bool {
+	parsed, err := semver.NewVersion([]byte(version))
+	if err != nil {
+		return false
+	}
+	ranges, err := parseRangeExpression(rangeExpression)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(ranges, func(r semver.Range) bool { return r.Contains(parsed) })
+}
+
+// renderAppStatuses renders the support statuses as a Markdown table.
+func renderAppStatuses(statuses []AppDependencyStatus, writer io.Writer) {
+	if len(statuses) == 0 {
+		io.WriteString(writer, "No instrumented modules are installed.\n")
+		return
+	}
+
+	outputTable := table.NewWriter()
+	outputTable.AppendHeader(table.Row{"Package name", "Installed version", "Status", "Details"})
+	for _, status := range statuses {
+		outputTable.AppendRow(table.Row{
+			fmt.Sprintf("`%s`", status.Name),
+			status.InstalledVersion,
+			status.Status,
+			status.Details,
+		})
+	}
+	io.WriteString(writer, outputTable.RenderMarkdown())
+	io.WriteString(writer, "\n")
+}
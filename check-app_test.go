@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkInstalledVersion(t *testing.T) {
+	info := ReleaseData{
+		Name:                "koa",
+		MinSupportedVersion: "2.0.0",
+		LatestVersion:       "2.16.1",
+		MinAgentVersion:     "3.2.0",
+		TestedVersions:      []string{"2.16.0", "2.0.0"},
+		KnownIncompatible:   []IncompatibleRange{{Versions: ">=2.5.0 <2.6.0", Comment: "Broken."}},
+	}
+
+	tests := []struct {
+		version string
+		status  string
+		details string
+	}{
+		{version: "1.7.0", status: appStatusTooOld, details: "minimum supported version is 2.0.0"},
+		{version: "2.5.1", status: appStatusIncompatible, details: "`>=2.5.0 <2.6.0` is not supported: Broken."},
+		{version: "2.14.1", status: appStatusSupported, details: "instrumented since 3.2.0"},
+		{version: "2.16.1", status: appStatusNewer, details: "tested up to 2.16.0"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			status, details := checkInstalledVersion(tc.version, info)
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.details, details)
+		})
+	}
+
+	t.Run("uses the latest version when tested versions are unknown", func(t *testing.T) {
+		untested := info
+		untested.TestedVersions = nil
+		status, _ := checkInstalledVersion("2.16.1", untested)
+		assert.Equal(t, appStatusSupported, status)
+	})
+
+	t.Run("versions between tested ranges are untested", func(t *testing.T) {
+		data, err := readReleaseDataFile("testdata/check-app-gap-report.json")
+		require.Nil(t, err)
+
+		status, details := checkInstalledVersion("3.1.0", data[0])
+		assert.Equal(t, appStatusUntested, status)
+		assert.Equal(t, "not within a tested range: `>=2 <3`, `>=4`", details)
+
+		status, _ = checkInstalledVersion("2.2.0", data[0])
+		assert.Equal(t, appStatusSupported, status)
+		status, _ = checkInstalledVersion("4.1.0", data[0])
+		assert.Equal(t, appStatusSupported, status)
+	})
+
+	t.Run("a latest range only covers the latest version", func(t *testing.T) {
+		latest := info
+		latest.TestedVersions = nil
+		latest.Tests = []TargetTest{{Versions: "latest"}}
+		status, _ := checkInstalledVersion("2.16.1", latest)
+		assert.Equal(t, appStatusSupported, status)
+		status, _ = checkInstalledVersion("2.14.1", latest)
+		assert.Equal(t, appStatusUntested, status)
+	})
+}
+
+func Test_runCheckApp(t *testing.T) {
+	data, err := readReleaseDataFile("testdata/check-app-report.json")
+	require.Nil(t, err)
+
+	t.Run("renders markdown", func(t *testing.T) {
+		builder := &strings.Builder{}
+		err := runCheckApp("testdata/lockfiles/npm-v1/package-lock.json", data, outputFormatMarkdown, builder)
+		assert.ErrorIs(t, err, ErrUnsupportedDependencies)
+		assert.ErrorContains(t, err, "2 dependency(ies)")
+
+		found := builder.String()
+		assert.Contains(t, found, "| `express` | 4.18.2 | known incompatible | `4.18.2` is not supported: Breaks the router. |")
+		assert.Contains(t, found, "| `koa` | 1.7.0 | too old | minimum supported version is 2.0.0 |")
+		assert.Contains(t, found, "| `koa` | 2.14.1 | supported | instrumented since 3.2.0 |")
+		assert.NotContains(t, found, "`debug`")
+	})
+
+	t.Run("renders json", func(t *testing.T) {
+		builder := &strings.Builder{}
+		err := runCheckApp("testdata/lockfiles/pnpm-v9/pnpm-lock.yaml", data, outputFormatJson, builder)
+		assert.ErrorIs(t, err, ErrUnsupportedDependencies)
+		assert.Contains(t, builder.String(), `"status": "newer than tested"`)
+		assert.Contains(t, builder.String(), `"details": "tested up to 2.5.0"`)
+	})
+
+	t.Run("reads an SBOM", func(t *testing.T) {
+		builder := &strings.Builder{}
+		err := runCheckApp("testdata/sbom/app.spdx.json", data, outputFormatMarkdown, builder)
+		assert.ErrorIs(t, err, ErrUnsupportedDependencies)
+		assert.Contains(t, builder.String(), "| `koa` | 1.7.0 | too old | minimum supported version is 2.0.0 |")
+	})
+
+	t.Run("reports lockfile errors", func(t *testing.T) {
+		err := runCheckApp("testdata/lockfiles/nope/yarn.lock", data, outputFormatMarkdown, &strings.Builder{})
+		assert.ErrorContains(t, err, "failed to read lockfile")
+	})
+}
+
+func Test_readReleaseDataFile(t *testing.T) {
+	_, err := readReleaseDataFile("testdata/lockfiles/yarn-classic/yarn.lock")
+	assert.ErrorContains(t, err, "failed to parse report")
+
+	_, err = readReleaseDataFile("testdata/nope.json")
+	assert.ErrorContains(t, err, "failed to open report")
+}
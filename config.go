@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// configEnvPrefix prefixes the name of the environment variable of every
+// option, see [optionBinding.envName].
+const configEnvPrefix = "NRVERSIONS_"
+
+// defaultConfigFile is the config file that is read, when it exists, if no
+// config file is given.
+const defaultConfigFile = ".nrversions.yaml"
+
+const (
+	optionSourceDefault = "default"
+	optionSourceConfig  = "config"
+	optionSourceEnv     = "env"
+	optionSourceFlag    = "flag"
+)
+
+// optionBinding binds an option to its name, which is the long name of its
+// flag as well as its key in the config file, and from which the name of its
+// environment variable is derived.
+type optionBinding struct {
+	name string
+
+	// value returns a pointer to the option within the options, either a
+	// `*string` or a `*bool`.
+	value func(opts *Options) any
+}
+
+// optionBindings lists every option that may be set by a flag, an
+// environment variable, or the config file.
+var optionBindings = []optionBinding{
+	{name: "agent-version", value: func(opts *Options) any { return &opts.AgentVersion }},
+	{name: "ai-compat-json", value: func(opts *Options) any { return &opts.AiCompatJsonFile }},
+	{name: "details", value: func(opts *Options) any { return &opts.ShowDetails }},
+	{name: "end-marker", value: func(opts *Options) any { return &opts.EndMarker }},
+	{name: "exclude", value: func(opts *Options) any { return &opts.Exclude }},
+	{name: "format", value: func(opts *Options) any { return &opts.OutputFormat }},
+	{name: "include", value: func(opts *Options) any { return &opts.Include }},
+	{name: "installed-agent-version", value: func(opts *Options) any { return &opts.InstalledAgentVersion }},
+	{name: "log-file", value: func(opts *Options) any { return &opts.LogFile }},
+	{name: "log-format", value: func(opts *Options) any { return &opts.LogFormat }},
+	{name: "no-externals", value: func(opts *Options) any { return &opts.NoExternals }},
+	{name: "node-matrix", value: func(opts *Options) any { return &opts.NodeMatrix }},
+	{name: "recursive", value: func(opts *Options) any { return &opts.Recursive }},
+	{name: "replace-in-file", value: func(opts *Options) any { return &opts.ReplaceInFile }},
+	{name: "repo-dir", value: func(opts *Options) any { return &opts.RepoDir }},
+	{name: "report", value: func(opts *Options) any { return &opts.ReportFile }},
+	{name: "skip-missing-files", value: func(opts *Options) any { return &opts.SkipMissingFiles }},
+	{name: "start-marker", value: func(opts *Options) any { return &opts.StartMarker }},
+	{name: "strict", value: func(opts *Options) any { return &opts.Strict }},
+	{name: "test-dir", value: func(opts *Options) any { return &opts.TestDir }},
+	{name: "tested-versions", value: func(opts *Options) any { return &opts.ShowTestedVersions }},
+	{name: "verbose", value: func(opts *Options) any { return &opts.Verbose }},
+}
+
+// legacyEnvNames maps options to the environment variables that were used
+// to set them before every option could be set from the environment. They
+// are read when the prefixed variable is not set.
+var legacyEnvNames = map[string]string{
+	"end-marker":   "END_MARKER",
+	"start-marker": "START_MARKER",
+}
+
+// envName is the name of the environment variable of the option, e.g.
+// `NRVERSIONS_REPO_DIR` for `repo-dir`.
+func (ob optionBinding) envName() string {
+	return configEnvPrefix + strings.ToUpper(strings.ReplaceAll(ob.name, "-", "_"))
+}
+
+// defaultOptions creates the options as they are when nothing is configured.
+func defaultOptions() Options {
+	opts := Options{
+		LogFormat:    logFormatPretty,
+		OutputFormat: outputFormatMarkdown,
+		StartMarker:  "{/* begin: compat-table */}",
+		EndMarker:    "{/* end: compat-table */}",
+		Sources:      make(map[string]string),
+	}
+	for _, binding := range optionBindings {
+		opts.Sources[binding.name] = optionSourceDefault
+	}
+	return opts
+}
+
+// applyConfigFile sets the options found in the config file. When `path` is
+// empty, the `NRVERSIONS_CONFIG` environment variable names the file, and
+// otherwise [defaultConfigFile] is read if it exists. An error wrapping
+// [ErrInvalidConfig] is returned for unknown keys, or values of the wrong
+// type.
+func applyConfigFile(opts *Options, path string) error {
+	required := true
+	if path == "" {
+		path = os.Getenv(configEnvPrefix + "CONFIG")
+	}
+	if path == "" {
+		path = defaultConfigFile
+		required = false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if required == false && errors.Is(err, fs.ErrNotExist) == true {
+			return nil
+		}
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	opts.ConfigFile = path
+
+	var values map[string]yaml.Node
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return fmt.Errorf("%w: `%s`: %w", ErrInvalidConfig, path, err)
+	}
+
+	for key, node := range values {
+		binding, found := findOptionBinding(key)
+		if found == false {
+			return fmt.Errorf("%w: `%s` line %d: unknown option `%s`", ErrInvalidConfig, path, node.Line, key)
+		}
+		err = node.Decode(binding.value(opts))
+		if err != nil {
+			return fmt.Errorf("%w: `%s` line %d: option `%s`: %w", ErrInvalidConfig, path, node.Line, key, err)
+		}
+		opts.Sources[binding.name] = optionSourceConfig
+	}
+	return nil
+}
+
+// applyEnvironment sets the options found in the environment. An error
+// wrapping [ErrInvalidConfig] is returned for boolean options whose value
+// cannot be parsed.
+func applyEnvironment(opts *Options) error {
+	for _, binding := range optionBindings {
+		name := binding.envName()
+		value, isSet := os.LookupEnv(name)
+		if isSet == false && legacyEnvNames[binding.name] != "" {
+			name = legacyEnvNames[binding.name]
+			value, isSet = os.LookupEnv(name)
+		}
+		if isSet == false {
+			continue
+		}
+
+		switch target := binding.value(opts).(type) {
+		case *bool:
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%w: %s: %w", ErrInvalidConfig, name, err)
+			}
+			*target = parsed
+		case *string:
+			*target = value
+		}
+		opts.Sources[binding.name] = optionSourceEnv
+	}
+	return nil
+}
+
+// findOptionBinding finds the binding of the named option.
+func findOptionBinding(name string) (optionBinding, bool) {
+	for _, binding := range optionBindings {
+		if binding.name == name {
+			return binding, true
+		}
+	}
+	return optionBinding{}, false
+}
+
+// runConfigShow writes the effective configuration to the writer. The
+// output is a valid config file, in which each option is annotated with
+// where its value came from.
+func runConfigShow(opts Options, writer io.Writer) error {
+	configFile := opts.ConfigFile
+	if configFile == "" {
+		configFile = "none"
+	}
+	io.WriteString(writer, fmt.Sprintf("# config file: %s\n", configFile))
+
+	for _, binding := range optionBindings {
+		value, err := yaml.Marshal(binding.value(&opts))
+		if err != nil {
+			return err
+		}
+		source := opts.Sources[binding.name]
+		switch source {
+		case optionSourceEnv:
+			name := binding.envName()
+			if _, isSet := os.LookupEnv(name); isSet == false && legacyEnvNames[binding.name] != "" {
+				name = legacyEnvNames[binding.name]
+			}
+			source = fmt.Sprintf("%s (%s)", source, name)
+		case optionSourceFlag:
+			source = fmt.Sprintf("%s (--%s)", source, binding.name)
+		}
+		io.WriteString(
+			writer,
+			fmt.Sprintf("%s: %s # %s\n", binding.name, strings.TrimSuffix(string(value), "\n"), source),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/integrii/flaggy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_optionBindings(t *testing.T) {
+	t.Run("every flag is bound", func(t *testing.T) {
+		opts := defaultOptions()
+		parser := newParser(&opts)
+		for _, cmd := range append([]*flaggy.Subcommand{&parser.Subcommand}, parser.Subcommands...) {
+			for _, f := range cmd.Flags {
+				if f.ShortName == "config" {
+					continue
+				}
+				_, found := findOptionBinding(f.ShortName)
+				assert.Equal(t, true, found, "flag %s of %s is not bound", f.ShortName, cmd.Name)
+			}
+		}
+	})
+
+	t.Run("derives environment variable names", func(t *testing.T) {
+		binding, _ := findOptionBinding("skip-missing-files")
+		assert.Equal(t, "NRVERSIONS_SKIP_MISSING_FILES", binding.envName())
+	})
+}
+
+func Test_applyConfigFile(t *testing.T) {
+	t.Run("reads options", func(t *testing.T) {
+		opts := defaultOptions()
+		err := applyConfigFile(&opts, "testdata/config/nrversions.yaml")
+		require.Nil(t, err)
+
+		assert.Equal(t, "testdata/config/nrversions.yaml", opts.ConfigFile)
+		assert.Equal(t, "../node-newrelic", opts.RepoDir)
+		assert.Equal(t, "test/versioned,test/versioned-external", opts.TestDir)
+		assert.Equal(t, true, opts.NoExternals)
+		assert.Equal(t, "json", opts.OutputFormat)
+		assert.Equal(t, "20,22", opts.NodeMatrix)
+		assert.Equal(t, optionSourceConfig, opts.Sources["repo-dir"])
+		assert.Equal(t, optionSourceDefault, opts.Sources["verbose"])
+	})
+
+	t.Run("reads the file named by the environment", func(t *testing.T) {
+		t.Setenv("NRVERSIONS_CONFIG", "testdata/config/nrversions.yaml")
+		opts := defaultOptions()
+		err := applyConfigFile(&opts, "")
+		require.Nil(t, err)
+		assert.Equal(t, "../node-newrelic", opts.RepoDir)
+	})
+
+	t.Run("ignores a missing default file", func(t *testing.T) {
+		opts := defaultOptions()
+		err := applyConfigFile(&opts, "")
+		require.Nil(t, err)
+		assert.Equal(t, "", opts.ConfigFile)
+	})
+
+	t.Run("errors for a missing file", func(t *testing.T) {
+		opts := defaultOptions()
+		err := applyConfigFile(&opts, "testdata/config/missing.yaml")
+		assert.ErrorContains(t, err, "failed to read config file")
+	})
+
+	t.Run("errors for unknown keys", func(t *testing.T) {
+		opts := defaultOptions()
+		err := applyConfigFile(&opts, "testdata/config/unknown-key.yaml")
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.ErrorContains(t, err, "line 2: unknown option `repo`")
+	})
+
+	t.Run("errors for values of the wrong type", func(t *testing.T) {
+		opts := defaultOptions()
+		err := applyConfigFile(&opts, "testdata/config/wrong-type.yaml")
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.ErrorContains(t, err, "option `strict`")
+	})
+}
+
+func Test_applyEnvironment(t *testing.T) {
+	t.Run("reads options", func(t *testing.T) {
+		t.Setenv("NRVERSIONS_REPO_DIR", "../agent")
+		t.Setenv("NRVERSIONS_VERBOSE", "true")
+		opts := defaultOptions()
+		err := applyEnvironment(&opts)
+		require.Nil(t, err)
+
+		assert.Equal(t, "../agent", opts.RepoDir)
+		assert.Equal(t, true, opts.Verbose)
+		assert.Equal(t, optionSourceEnv, opts.Sources["verbose"])
+	})
+
+	t.Run("keeps the agent versions of generate and advise apart", func(t *testing.T) {
+		t.Setenv("NRVERSIONS_INSTALLED_AGENT_VERSION", "11.5.0")
+		opts := defaultOptions()
+		err := applyEnvironment(&opts)
+		require.Nil(t, err)
+
+		assert.Equal(t, "11.5.0", opts.InstalledAgentVersion)
+		assert.Equal(t, "", opts.AgentVersion)
+	})
+
+	t.Run("reads the legacy marker variables", func(t *testing.T) {
+		t.Setenv("START_MARKER", "<!-- begin -->")
+		t.Setenv("END_MARKER", "<!-- end -->")
+		t.Setenv("NRVERSIONS_END_MARKER", "<!-- stop -->")
+		opts := defaultOptions()
+		err := applyEnvironment(&opts)
+		require.Nil(t, err)
+
+		assert.Equal(t, "<!-- begin -->", opts.StartMarker)
+		assert.Equal(t, "<!-- stop -->", opts.EndMarker)
+	})
+
+	t.Run("errors for invalid booleans", func(t *testing.T) {
+		t.Setenv("NRVERSIONS_STRICT", "sometimes")
+		opts := defaultOptions()
+		err := applyEnvironment(&opts)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		assert.ErrorContains(t, err, "NRVERSIONS_STRICT")
+	})
+}
+
+func Test_ParseOptions_precedence(t *testing.T) {
+	t.Setenv("NRVERSIONS_CONFIG", "testdata/config/nrversions.yaml")
+	t.Setenv("NRVERSIONS_FORMAT", "terminal")
+	t.Setenv("NRVERSIONS_TEST_DIR", "test/env")
+
+	opts, err := ParseOptions([]string{"--test-dir", "test/flag", "config", "show"})
+	require.Nil(t, err)
+
+	assert.Equal(t, commandConfigShow, opts.Command)
+	assert.Equal(t, "test/flag", opts.TestDir)
+	assert.Equal(t, optionSourceFlag, opts.Sources["test-dir"])
+	assert.Equal(t, "terminal", opts.OutputFormat)
+	assert.Equal(t, optionSourceEnv, opts.Sources["format"])
+	assert.Equal(t, "../node-newrelic", opts.RepoDir)
+	assert.Equal(t, optionSourceConfig, opts.Sources["repo-dir"])
+	assert.Equal(t, "", opts.Include)
+	assert.Equal(t, optionSourceDefault, opts.Sources["include"])
+}
+
+func Test_runConfigShow(t *testing.T) {
+	opts := defaultOptions()
+	opts.ConfigFile = "nrversions.yaml"
+	opts.RepoDir = "../node-newrelic"
+	opts.Sources["repo-dir"] = optionSourceConfig
+	opts.Verbose = true
+	opts.Sources["verbose"] = optionSourceFlag
+	t.Setenv("NRVERSIONS_STRICT", "1")
+	opts.Strict = true
+	opts.Sources["strict"] = optionSourceEnv
+
+	buf := &bytes.Buffer{}
+	err := runConfigShow(opts, buf)
+	require.Nil(t, err)
+
+	found := buf.String()
+	assert.Contains(t, found, "# config file: nrversions.yaml\n")
+	assert.Contains(t, found, "format: markdown # default\n")
+	assert.Contains(t, found, "repo-dir: ../node-newrelic # config\n")
+	assert.Contains(t, found, "strict: true # env (NRVERSIONS_STRICT)\n")
+	assert.Contains(t, found, "verbose: true # flag (--verbose)\n")
+	assert.Contains(t, found, "start-marker: '{/* begin: compat-table */}' # default\n")
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"blitznote.com/src/semver/v3"
+)
+
+var ErrCoverageGaps = errors.New("coverage gaps found")
+
+// CoverageGap describes the mismatches between the declared targets and the
+// tested dependencies of a single versioned test directory.
+type CoverageGap struct {
+	// Dir is the versioned test directory, i.e. the directory that contains
+	// the `package.json`.
+	Dir string
+
+	// UntestedTargets lists the declared targets that are not a dependency of
+	// any test block. Such targets are omitted from the generated documents.
+	UntestedTargets []string
+
+	// UndeclaredDependencies lists the tested dependencies that look like
+	// instrumented modules, but are not declared as targets.
+	UndeclaredDependencies []string
+}
+
+// runGaps writes a coverage gap report for every versioned test directory
+// reachable from the given directory to the writer. An error wrapping
+// [ErrCoverageGaps] is returned if any gaps were found.
+func runGaps(dir string, opts discoveryOptions, writer io.Writer) error {
+	gaps, errs := findCoverageGaps(dir, opts)
+	for _, err := range errs {
+		io.WriteString(writer, fmt.Sprintf("error: %s\n", err))
+	}
+	renderCoverageGaps(gaps, writer)
+
+	if len(gaps) > 0 {
+		return fmt.Errorf("%w: %d directory(ies)", ErrCoverageGaps, len(gaps))
+	}
+	return nil
+}
+
+// findCoverageGaps iterates a versioned tests directory and collects the
+// coverage gaps of each versioned test directory within it. Targets declared
+// in any of the directories are considered instrumented modules when looking
+// for undeclared dependencies in the others. Directories without gaps are
+// omitted from the result.
+func findCoverageGaps(dir string, opts discoveryOptions) ([]CoverageGap, []error) {
+	type testPackage struct {
+		path string
+		pkg  *VersionedTestPackageJson
+	}
+
+	errs := make([]error, 0)
+	packages := make([]testPackage, 0)
+	knownTargets := make(map[string]bool)
+
+	iterChan := make(chan dirIterChan)
+	go iterateTestDir(dir, opts, iterChan)
+	for result := range iterChan {
+		if result.err != nil {
+			errs = append(errs, result.err)
+			continue
+		}
+		packages = append(packages, testPackage{path: result.path, pkg: result.pkg})
+		for _, target := range result.pkg.Targets {
+			knownTargets[target.Name] = true
+		}
+	}
+
+	results := make([]CoverageGap, 0)
+	for _, p := range packages {
+		gap := CoverageGap{
+			Dir:                    filepath.Dir(p.path),
+			UntestedTargets:        untestedTargets(p.pkg),
+			UndeclaredDependencies: undeclaredDependencies(p.pkg, knownTargets),
+		}
+		if len(gap.UntestedTargets) > 0 || len(gap.UndeclaredDependencies) > 0 {
+			results = append(results, gap)
+		}
+	}
+
+	slices.SortFunc(results, func(a CoverageGap, b CoverageGap) int {
+		return strings.Compare(a.Dir, b.Dir)
+	})
+	return results, errs
+}
+
+// untestedTargets returns the names of the declared targets that are not a
+// dependency of any test block.
+func untestedTargets(pkg *VersionedTestPackageJson) []string {
+	results := make([]string, 0)
+	for _, target := range pkg.Targets {
+		if isTargetTested(target, pkg.Tests) == false {
+			results = append(results, target.Name)
+		}
+	}
+	return results
+}
+
+// undeclaredDependencies returns the sorted names of the tested dependencies
+// that look like instrumented modules, but are not declared as targets.
+func undeclaredDependencies(pkg *VersionedTestPackageJson, knownTargets map[string]bool) []string {
+	results := make([]string, 0)
+	for _, test := range pkg.Tests {
+		for name, dep := range test.Dependencies {
+			spec := parseDependencySpec(name, dep.Versions)
+			declared := slices.ContainsFunc(pkg.Targets, func(t Target) bool { return t.Name == spec.Package })
+			if declared == true || slices.Contains(results, spec.Package) == true {
+				continue
+			}
+			if knownTargets[spec.Package] == true || looksInstrumented(spec) == true {
+				results = append(results, spec.Package)
+			}
+		}
+	}
+	slices.Sort(results)
+	return results
+}
+
+// looksInstrumented guesses whether a tested dependency is a module that is
+// under test, as opposed to a helper that the tests rely upon. Helpers are
+// usually pinned to a single version, or are packages published by New
+// Relic, while modules under test are tested across a range of versions.
+func looksInstrumented(spec DependencySpec) bool {
+	if strings.HasPrefix(spec.Package, "@newrelic/") == true || spec.Package == "newrelic" {
+		return false
+	}
+	if spec.Kind != specKindRange {
+		return false
+	}
+
+	versions := spec.Range
+	if versions == "" || versions == "latest" {
+		return false
+	}
+	_, err := semver.NewVersion([]byte(strings.TrimPrefix(strings.TrimPrefix(versions, "="), "v")))
+	if err == nil {
+		// An exact version.
+		return false
+	}
+
+	_, err = parseRangeExpression(versions)
+	return err == nil
+}
+
+// renderCoverageGaps writes a plain text report of the coverage gaps.
+func renderCoverageGaps(gaps []CoverageGap, writer io.Writer) {
+	for _, gap := range gaps {
+		io.WriteString(writer, gap.Dir+":\n")
+		for _, name := range gap.UntestedTargets {
+			io.WriteString(writer, fmt.Sprintf("  target `%s` is not tested by any test block\n", name))
+		}
+		for _, name := range gap.UndeclaredDependencies {
+			io.WriteString(writer, fmt.Sprintf("  dependency `%s` is tested but not declared as a target\n", name))
+		}
+	}
+}
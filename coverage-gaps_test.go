@@ -0,0 +1,64 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_runGaps(t *testing.T) {
+	t.Run("reports no gaps for a covered directory", func(t *testing.T) {
+		builder := strings.Builder{}
+		err := runGaps("testdata/gaps/b", discoveryOptions{}, &builder)
+		assert.Nil(t, err)
+		assert.Empty(t, builder.String())
+	})
+
+	t.Run("reports gaps per directory", func(t *testing.T) {
+		builder := strings.Builder{}
+		err := runGaps("testdata/gaps", discoveryOptions{}, &builder)
+		assert.ErrorIs(t, err, ErrCoverageGaps)
+
+		expected := heredoc.Docf(`
+			%s:
+			  target %sbar%s is not tested by any test block
+			  dependency %squx%s is tested but not declared as a target
+			%s:
+			  dependency %sbaz%s is tested but not declared as a target
+		`,
+			filepath.Join("testdata", "gaps", "a"),
+			"`", "`",
+			"`", "`",
+			filepath.Join("testdata", "gaps", "c"),
+			"`", "`",
+		)
+		assert.Equal(t, expected, builder.String())
+	})
+}
+
+func Test_looksInstrumented(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions string
+		expected bool
+	}{
+		{name: "foo", versions: ">=1.0.0", expected: true},
+		{name: "foo", versions: "^2.0.0 || ^3.0.0", expected: true},
+		{name: "foo", versions: "1.2.3", expected: false},
+		{name: "foo", versions: "=1.2.3", expected: false},
+		{name: "foo", versions: "latest", expected: false},
+		{name: "foo", versions: "next", expected: false},
+		{name: "foo", versions: "^bogus", expected: false},
+		{name: "foo", versions: "github:foo/bar", expected: false},
+		{name: "foo-v2", versions: "npm:foo@^2.0.0", expected: true},
+		{name: "@newrelic/test-utilities", versions: "^8.0.0", expected: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name+" "+tc.versions, func(t *testing.T) {
+			assert.Equal(t, tc.expected, looksInstrumented(parseDependencySpec(tc.name, tc.versions)))
+		})
+	}
+}
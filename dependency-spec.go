@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// specKindRange is a semver range, or `latest`, of a registry package.
+	specKindRange = "range"
+
+	// specKindTag is a dist-tag, e.g. `next`, of a registry package.
+	specKindTag = "tag"
+
+	// specKindGit is a git repository or a remote tarball.
+	specKindGit = "git"
+
+	// specKindFile is a path on the local file system.
+	specKindFile = "file"
+)
+
+// Covers strings like `next`, `beta`, or `v4-lts`.
+var distTagRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._-]*$`)
+
+// Covers GitHub shorthand strings like `user/repo` or `user/repo#ref`.
+var gitShorthandRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+/[A-Za-z0-9._-]+(#.*)?$`)
+
+var gitSpecPrefixes = []string{
+	"git+", "git://", "github:", "gitlab:", "bitbucket:", "gist:", "http://", "https://",
+}
+
+var fileSpecPrefixes = []string{"file:", "link:", "./", "../", "/", "~/"}
+
+// DependencySpec is the parsed form of the version specifier of a versioned
+// test dependency.
+type DependencySpec struct {
+	Kind string
+
+	// Package is the name of the registry package that is installed. It
+	// differs from the dependency name when the specifier is an alias, e.g.
+	// `npm:@scope/pkg@^2`.
+	Package string
+
+	// Range is the range of the registry package that is installed. It is
+	// only set for specifiers of [specKindRange].
+	Range string
+
+	// Tag is the dist-tag of the registry package that is installed. It is
+	// only set for specifiers of [specKindTag].
+	Tag string
+}
+
+// parseDependencySpec parses the version specifier of a dependency in the
+// same manner as npm. Specifiers that are neither recognizable as a git,
+// file, or dist-tag specifier are considered ranges, even if they are not
+// valid ranges.
+func parseDependencySpec(name string, versions string) DependencySpec {
+	raw := strings.TrimSpace(versions)
+
+	if strings.HasPrefix(raw, "npm:") == true {
+		aliased := strings.TrimPrefix(raw, "npm:")
+		// An `@` at the start is part of a scoped package name, not the
+		// version separator.
+		idx := strings.LastIndex(aliased, "@")
+		if idx <= 0 {
+			return parseDependencySpec(aliased, "latest")
+		}
+		return parseDependencySpec(aliased[0:idx], aliased[idx+1:])
+	}
+
+	for _, prefix := range fileSpecPrefixes {
+		if strings.HasPrefix(raw, prefix) == true {
+			return DependencySpec{Kind: specKindFile, Package: name}
+		}
+	}
+	for _, prefix := range gitSpecPrefixes {
+		if strings.HasPrefix(raw, prefix) == true {
+			return DependencySpec{Kind: specKindGit, Package: name}
+		}
+	}
+	if gitShorthandRegex.MatchString(raw) == true {
+		return DependencySpec{Kind: specKindGit, Package: name}
+	}
+
+	if raw != "latest" && distTagRegex.MatchString(raw) == true {
+		_, err := parseRangeExpression(raw)
+		if err != nil {
+			return DependencySpec{Kind: specKindTag, Package: name, Tag: raw}
+		}
+	}
+
+	return DependencySpec{Kind: specKindRange, Package: name, Range: raw}
+}
+
+// dependsOn determines if any dependency of the test block installs the
+// named package, whether directly, through an alias, or from a non-registry
+// source.
+func dependsOn(test TestDescription, packageName string) bool {
+	for name, dep := range test.Dependencies {
+		if parseDependencySpec(name, dep.Versions).Package == packageName {
+			return true
+		}
+	}
+	return false
+}
+
+// registryDependencies returns the dependencies of the test block that
+// install a range of the named registry package. The returned blocks have
+// their versions replaced with the range that is installed, so aliases are
+// unwrapped. Dependencies on git or file sources, and dist-tags that have
+// not been resolved, are omitted. The blocks are ordered by dependency name.
+func registryDependencies(test TestDescription, packageName string) []DependencyBlock {
+	names := make([]string, 0, len(test.Dependencies))
+	for name := range test.Dependencies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	results := make([]DependencyBlock, 0)
+	for _, name := range names {
+		dep := test.Dependencies[name]
+		spec := parseDependencySpec(name, dep.Versions)
+		if spec.Package != packageName || spec.Kind != specKindRange {
+			continue
+		}
+		results = append(results, DependencyBlock{Versions: spec.Range, Samples: dep.Samples})
+	}
+	return results
+}
+
+// resolveDistTags replaces every dist-tag specifier within the package with
+// the version the tag currently points to in the registry. Aliases are
+// retained. An error is returned for every tag that could not be resolved;
+// such dependencies are left as they are.
+func resolveDistTags(pkg *VersionedTestPackageJson, packuments *packumentCache) []error {
+	errs := make([]error, 0)
+	for _, test := range pkg.Tests {
+		for name, dep := range test.Dependencies {
+			spec := parseDependencySpec(name, dep.Versions)
+			if spec.Kind != specKindTag {
+				continue
+			}
+
+			detailedInfo, err := packuments.Get(spec.Package)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("could not resolve dist-tag `%s` of `%s`: %w", spec.Tag, spec.Package, err))
+				continue
+			}
+			version, found := detailedInfo.DistTags[spec.Tag]
+			if found == false {
+				errs = append(errs, fmt.Errorf("`%s` has no dist-tag `%s`", spec.Package, spec.Tag))
+				continue
+			}
+
+			if spec.Package == name {
+				dep.Versions = version
+			} else {
+				dep.Versions = fmt.Sprintf("npm:%s@%s", spec.Package, version)
+			}
+			test.Dependencies[name] = dep
+		}
+	}
+	return errs
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseDependencySpec(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions string
+		expected DependencySpec
+	}{
+		{
+			name:     "foo",
+			versions: ">=1.0.0 <2.0.0",
+			expected: DependencySpec{Kind: specKindRange, Package: "foo", Range: ">=1.0.0 <2.0.0"},
+		},
+		{
+			name:     "foo",
+			versions: "latest",
+			expected: DependencySpec{Kind: specKindRange, Package: "foo", Range: "latest"},
+		},
+		{
+			name:     "foo",
+			versions: "next",
+			expected: DependencySpec{Kind: specKindTag, Package: "foo", Tag: "next"},
+		},
+		{
+			name:     "bar-v2",
+			versions: "npm:@scope/bar@^2",
+			expected: DependencySpec{Kind: specKindRange, Package: "@scope/bar", Range: "^2"},
+		},
+		{
+			name:     "bar-beta",
+			versions: "npm:@scope/bar@beta",
+			expected: DependencySpec{Kind: specKindTag, Package: "@scope/bar", Tag: "beta"},
+		},
+		{
+			name:     "bar",
+			versions: "npm:@scope/bar",
+			expected: DependencySpec{Kind: specKindRange, Package: "@scope/bar", Range: "latest"},
+		},
+		{
+			name:     "foo",
+			versions: "git+https://github.com/foo/foo.git#v1.0.0",
+			expected: DependencySpec{Kind: specKindGit, Package: "foo"},
+		},
+		{
+			name:     "foo",
+			versions: "foo/foo#main",
+			expected: DependencySpec{Kind: specKindGit, Package: "foo"},
+		},
+		{
+			name:     "foo",
+			versions: "https://example.com/foo.tgz",
+			expected: DependencySpec{Kind: specKindGit, Package: "foo"},
+		},
+		{
+			name:     "foo",
+			versions: "file:../foo",
+			expected: DependencySpec{Kind: specKindFile, Package: "foo"},
+		},
+		{
+			name:     "foo",
+			versions: "./foo",
+			expected: DependencySpec{Kind: specKindFile, Package: "foo"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.versions, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseDependencySpec(tc.name, tc.versions))
+		})
+	}
+}
+
+func Test_parsePackage_aliases(t *testing.T) {
+	pkg := readJsonFile(t, "testdata/alias-specifiers.json")
+	found, err := parsePackage(&pkg)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(found))
+
+	assert.Equal(t, "foo", found[0].Name)
+	assert.Equal(t, "1.2.0", found[0].MinVersion)
+	assert.Equal(t, []TargetTest{
+		{Versions: ">=3.0.0", Files: []string{"current.test.js"}},
+		{Versions: "^1.2.0", Files: []string{"legacy.test.js"}},
+	}, found[0].Tests)
+
+	assert.Equal(t, "@scope/bar", found[1].Name)
+	assert.Equal(t, "2.0.0", found[1].MinVersion)
+	assert.Equal(t, []TargetTest{
+		{Versions: "^2.0.0", Files: []string{"current.test.js"}},
+	}, found[1].Tests)
+}
+
+func Test_resolveDistTags(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/@scope/bar":
+			io.WriteString(res, `{"dist-tags":{"latest":"2.1.0","next":"3.0.0-beta.1"}}`)
+		default:
+			res.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+	packuments := newPackumentCache(NewNpmClient(WithBaseUrl(ts.URL)))
+
+	pkg := readJsonFile(t, "testdata/alias-specifiers.json")
+	pkg.Tests[0].Dependencies["bar-beta"] = DependencyBlock{Versions: "npm:@scope/bar@beta"}
+	pkg.Tests[0].Dependencies["baz"] = DependencyBlock{Versions: "next"}
+
+	errs := resolveDistTags(&pkg, packuments)
+	require.Equal(t, 2, len(errs))
+	assert.Contains(t, errs[0].Error()+errs[1].Error(), "`@scope/bar` has no dist-tag `beta`")
+	assert.Contains(t, errs[0].Error()+errs[1].Error(), "could not resolve dist-tag `next` of `baz`")
+	assert.Equal(t, "3.0.0-beta.1", pkg.Tests[2].Dependencies["@scope/bar"].Versions)
+	assert.Equal(t, "npm:@scope/bar@beta", pkg.Tests[0].Dependencies["bar-beta"].Versions)
+}
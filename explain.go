@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// runExplain writes how each field of the compatibility data of the named
+// module was derived to the writer. The data must have been processed with
+// [processOptions.trace] enabled. An error wrapping [ErrPackageNotFound] is
+// returned if the module is not instrumented.
+func runExplain(name string, data []ReleaseData, writer io.Writer) error {
+	idx := slices.IndexFunc(data, func(info ReleaseData) bool { return info.Name == name })
+	if idx < 0 {
+		return fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+	}
+	renderExplanation(data[idx], writer)
+	return nil
+}
+
+// explainWinner determines the source that supplied the versions of a row.
+// When sources were merged, this is the first source that supplied the
+// lowest minimum supported version, as chosen by [mergeData].
+func explainWinner(info ReleaseData) int {
+	return slices.IndexFunc(info.Sources, func(source ReleaseSource) bool {
+		return source.MinSupportedVersion == info.MinSupportedVersion
+	})
+}
+
+// renderExplanation renders the provenance of each field of a row as plain
+// text.
+func renderExplanation(info ReleaseData, writer io.Writer) {
+	var winner *ReleaseSource
+	var trace *DerivationTrace
+	if idx := explainWinner(info); idx >= 0 {
+		winner = &info.Sources[idx]
+		trace = winner.Trace
+	}
+
+	io.WriteString(writer, info.Name+"\n")
+
+	writeField := func(field string, value string, details ...string) {
+		io.WriteString(writer, fmt.Sprintf("  %s: %s\n", field, value))
+		for _, detail := range details {
+			io.WriteString(writer, "    "+detail+"\n")
+		}
+	}
+
+	writeField(
+		"minSupportedVersion",
+		info.MinSupportedVersion,
+		explainMinSupported(info, winner)...,
+	)
+	writeField(
+		"minSupportedVersionRelease",
+		info.MinSupportedVersionRelease,
+		explainResponse(trace, fmt.Sprintf(`time["%s"]`, info.MinSupportedVersion)),
+	)
+	writeField(
+		"latestVersion",
+		info.LatestVersion,
+		explainResponse(trace, "version"),
+	)
+	writeField(
+		"latestVersionRelease",
+		info.LatestVersionRelease,
+		explainResponse(trace, fmt.Sprintf(`time["%s"]`, info.LatestVersion)),
+	)
+	agentDetail := "no source supplied the versions"
+	if winner != nil {
+		agentDetail = fmt.Sprintf("declared by the target in %s", winner)
+	}
+	writeField("minAgentVersion", info.MinAgentVersion, agentDetail)
+
+	io.WriteString(writer, "  tests:\n")
+	for _, test := range info.Tests {
+		io.WriteString(
+			writer,
+			fmt.Sprintf("    %q (%s)\n", strings.TrimSpace(test.Versions), strings.Join(test.Files, ", ")),
+		)
+	}
+
+	io.WriteString(writer, "  sources:\n")
+	for i, source := range info.Sources {
+		outcome := "merged, its versions were dropped"
+		if winner == &info.Sources[i] {
+			outcome = "supplied the versions"
+		}
+		io.WriteString(
+			writer,
+			fmt.Sprintf(
+				"    %s: minSupportedVersion %s, minAgentVersion %s (%s)\n",
+				source,
+				source.MinSupportedVersion,
+				source.MinAgentVersion,
+				outcome,
+			),
+		)
+	}
+
+	for _, conflict := range info.Conflicts {
+		io.WriteString(writer, "  conflict: "+describeConflict(info.Name, conflict)+"\n")
+	}
+}
+
+// findResponse finds the registry response, recorded in the trace, that the
+// named field was read from.
+func findResponse(trace *DerivationTrace, field string) (RegistryResponse, bool) {
+	if trace == nil {
+		return RegistryResponse{}, false
+	}
+	idx := slices.IndexFunc(trace.Responses, func(response RegistryResponse) bool {
+		return response.Field == field
+	})
+	if idx < 0 {
+		return RegistryResponse{}, false
+	}
+	return trace.Responses[idx], true
+}
+
+// explainResponse describes the registry response a field was read from.
+func explainResponse(trace *DerivationTrace, field string) string {
+	response, found := findResponse(trace, field)
+	if found == false {
+		return "the registry response was not recorded"
+	}
+	return fmt.Sprintf("from %s of %s", field, response.Url)
+}
+
+// explainMinSupported describes how the minimum supported version was
+// derived from the trace of the source that supplied it.
+func explainMinSupported(info ReleaseData, source *ReleaseSource) []string {
+	if source == nil {
+		return []string{"no source supplied the versions, the derivation was not recorded"}
+	}
+	details := []string{fmt.Sprintf("supplied by %s", source)}
+	trace := source.Trace
+	if trace == nil {
+		return append(details, "the derivation was not recorded")
+	}
+
+	if trace.MinSupported != "" {
+		details = append(details, fmt.Sprintf("declared as minSupported %q by the target, the tests were not considered", trace.MinSupported))
+	} else {
+		details = append(details, "derived from the versioned test blocks:")
+		for _, candidate := range trace.Candidates {
+			details = append(
+				details,
+				fmt.Sprintf("  block %d (%s): %q", candidate.Test+1, strings.Join(candidate.Files, ", "), candidate.Versions),
+			)
+			quoted := make([]string, 0, len(candidate.Normalized))
+			for _, piece := range candidate.Normalized {
+				quoted = append(quoted, fmt.Sprintf("%q", piece))
+			}
+			details = append(details, "    normalized: "+strings.Join(quoted, ", "))
+			selected := fmt.Sprintf("    lowest: %q", candidate.Selected)
+			if candidate.Winner == true {
+				selected += " (the lowest of all blocks)"
+			}
+			details = append(details, selected)
+		}
+	}
+
+	details = append(details, fmt.Sprintf("lower boundary of %q: %s", trace.MinVersionRange, trace.LowerBoundary))
+	if trace.MinVersionRange == max_range {
+		if response, found := findResponse(trace, "version"); found == true {
+			details = append(details, fmt.Sprintf("resolved to the latest version from version of %s", response.Url))
+		}
+	} else if response, found := findResponse(trace, "versions"); found == true {
+		details = append(
+			details,
+			fmt.Sprintf("resolved to the lowest version in versions of %s that satisfies %q", response.Url, trace.MinVersionRange),
+		)
+	}
+	return details
+}
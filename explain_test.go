@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_traceTarget(t *testing.T) {
+	t.Run("records the ranges considered", func(t *testing.T) {
+		pkg := readJsonFile(t, "testdata/out-of-order-ranges.json")
+		trace, err := traceTarget(pkg.Targets[0], &pkg)
+		require.Nil(t, err)
+
+		expected := &DerivationTrace{
+			Candidates: []RangeCandidate{
+				{Test: 0, Files: []string{}, Versions: ">=4.0.0", Normalized: []string{">=4.0.0"}, Selected: ">=4.0.0"},
+				{
+					Test:       1,
+					Files:      []string{},
+					Versions:   ">=1.5.0",
+					Normalized: []string{">=1.5.0"},
+					Selected:   ">=1.5.0",
+					Winner:     true,
+				},
+			},
+			MinVersionRange: ">=1.5.0",
+			LowerBoundary:   "1.5.0",
+		}
+		assert.Equal(t, expected, trace)
+	})
+
+	t.Run("records the rewritten pieces of a range", func(t *testing.T) {
+		pkg := VersionedTestPackageJson{
+			Name:    "foo",
+			Targets: []Target{{Name: "foo"}},
+			Tests: []TestDescription{{
+				Supported:    true,
+				Dependencies: DependenciesBlock{"foo": {Versions: ">= 2 < 3 || >= 1.2.0"}},
+				Files:        FilesBlock{"foo.test.js"},
+			}},
+		}
+		trace, err := traceTarget(pkg.Targets[0], &pkg)
+		require.Nil(t, err)
+
+		require.Len(t, trace.Candidates, 1)
+		assert.Equal(t, []string{">=2 <3", ">=1.2.0"}, trace.Candidates[0].Normalized)
+		assert.Equal(t, ">=1.2.0", trace.Candidates[0].Selected)
+		assert.Equal(t, "1.2.0", trace.LowerBoundary)
+	})
+
+	t.Run("records a declared minimum", func(t *testing.T) {
+		pkg := readJsonFile(t, "testdata/versioned/aws-sdk-v3/package.json")
+		idx := 0
+		for i, target := range pkg.Targets {
+			if target.Name == "@smithy/smithy-client" {
+				idx = i
+			}
+		}
+		trace, err := traceTarget(pkg.Targets[idx], &pkg)
+		require.Nil(t, err)
+
+		assert.Equal(t, "2.0.0", trace.MinSupported)
+		assert.Empty(t, trace.Candidates)
+		assert.Equal(t, "2.0.0", trace.LowerBoundary)
+	})
+
+	t.Run("errors for a missing target", func(t *testing.T) {
+		pkg := VersionedTestPackageJson{Name: "foo", Targets: []Target{{Name: "bar"}}}
+		_, err := traceTarget(pkg.Targets[0], &pkg)
+		assert.ErrorIs(t, err, ErrTargetMissing)
+	})
+}
+
+func Test_runExplain(t *testing.T) {
+	data := []ReleaseData{{
+		Name:                       "foo",
+		MinSupportedVersion:        "1.5.1",
+		MinSupportedVersionRelease: "2020-01-02",
+		LatestVersion:              "4.2.0",
+		LatestVersionRelease:       "2024-05-06",
+		MinAgentVersion:            "2.0.0",
+		Tests: []TargetTest{
+			{Versions: ">=1.5.0", Files: []string{"foo.test.js"}},
+		},
+		Sources: []ReleaseSource{
+			{
+				Repo:                "agent",
+				File:                "foo/package.json",
+				MinSupportedVersion: "1.5.1",
+				MinAgentVersion:     "2.0.0",
+				Trace: &DerivationTrace{
+					Candidates: []RangeCandidate{{
+						Test:       0,
+						Files:      []string{"foo.test.js"},
+						Versions:   ">= 1.5.0",
+						Normalized: []string{">=1.5.0"},
+						Selected:   ">=1.5.0",
+						Winner:     true,
+					}},
+					MinVersionRange: ">=1.5.0",
+					LowerBoundary:   "1.5.0",
+					Responses: []RegistryResponse{
+						{Url: "https://registry.example.com/foo/latest", Field: "version", Value: "4.2.0"},
+						{Url: "https://registry.example.com/foo", Field: "versions", Value: "1.5.1"},
+						{Url: "https://registry.example.com/foo", Field: `time["1.5.1"]`, Value: "2020-01-02"},
+						{Url: "https://registry.example.com/foo", Field: `time["4.2.0"]`, Value: "2024-05-06"},
+					},
+				},
+			},
+			{
+				Repo:                "agent",
+				File:                "foo-esm/package.json",
+				MinSupportedVersion: "3.0.0",
+				MinAgentVersion:     "2.0.0",
+			},
+		},
+		Conflicts: []MergeConflict{{
+			Field: conflictFieldMinSupported,
+			Values: []ConflictValue{
+				{Value: "1.5.1", Sources: []ReleaseSource{{Repo: "agent", File: "foo/package.json"}}},
+				{Value: "3.0.0", Sources: []ReleaseSource{{Repo: "agent", File: "foo-esm/package.json"}}},
+			},
+		}},
+	}}
+
+	t.Run("explains each field", func(t *testing.T) {
+		expected := heredoc.Doc(`
+			foo
+			  minSupportedVersion: 1.5.1
+			    supplied by agent:foo/package.json
+			    derived from the versioned test blocks:
+			      block 1 (foo.test.js): ">= 1.5.0"
+			        normalized: ">=1.5.0"
+			        lowest: ">=1.5.0" (the lowest of all blocks)
+			    lower boundary of ">=1.5.0": 1.5.0
+			    resolved to the lowest version in versions of https://registry.example.com/foo that satisfies ">=1.5.0"
+			  minSupportedVersionRelease: 2020-01-02
+			    from time["1.5.1"] of https://registry.example.com/foo
+			  latestVersion: 4.2.0
+			    from version of https://registry.example.com/foo/latest
+			  latestVersionRelease: 2024-05-06
+			    from time["4.2.0"] of https://registry.example.com/foo
+			  minAgentVersion: 2.0.0
+			    declared by the target in agent:foo/package.json
+			  tests:
+			    ">=1.5.0" (foo.test.js)
+			  sources:
+			    agent:foo/package.json: minSupportedVersion 1.5.1, minAgentVersion 2.0.0 (supplied the versions)
+			    agent:foo-esm/package.json: minSupportedVersion 3.0.0, minAgentVersion 2.0.0 (merged, its versions were dropped)
+			  conflict: ` + describeConflict("foo", data[0].Conflicts[0]) + `
+		`)
+
+		buf := &bytes.Buffer{}
+		err := runExplain("foo", data, buf)
+		require.Nil(t, err)
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("does not claim sources that were not recorded", func(t *testing.T) {
+		unrecorded := []ReleaseData{{
+			Name:                "foo",
+			MinSupportedVersion: "1.5.1",
+			LatestVersion:       "4.2.0",
+			Sources:             []ReleaseSource{{Repo: "agent", File: "foo/package.json", MinSupportedVersion: "3.0.0"}},
+		}}
+
+		buf := &bytes.Buffer{}
+		err := runExplain("foo", unrecorded, buf)
+		require.Nil(t, err)
+		found := buf.String()
+		assert.Contains(t, found, "  minSupportedVersion: 1.5.1\n    no source supplied the versions, the derivation was not recorded\n")
+		assert.Contains(t, found, "  latestVersion: 4.2.0\n    the registry response was not recorded\n")
+		assert.Contains(t, found, "  minAgentVersion: \n    no source supplied the versions\n")
+		assert.NotContains(t, found, "supplied by")
+	})
+
+	t.Run("errors for an unknown module", func(t *testing.T) {
+		err := runExplain("bar", data, &bytes.Buffer{})
+		assert.ErrorIs(t, err, ErrPackageNotFound)
+	})
+}
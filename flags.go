@@ -3,22 +3,84 @@ package main
 import (
 	"github.com/MakeNowJust/heredoc/v2"
 	"github.com/integrii/flaggy"
-	"os"
+	"slices"
+	"strings"
 )
 
-type appFlags struct {
-	aiCompatJsonFile string
-	noExternals      bool
-	replaceInFile    string
-	repoDir          string
-	testDir          string
-	verbose          bool
+const outputFormatMarkdown = "markdown"
+const outputFormatJson = "json"
+const outputFormatTerminal = "terminal"
 
-	startMarker string
-	endMarker   string
-}
+const commandGenerate = "generate"
+const commandLint = "lint"
+const commandGaps = "gaps"
+const commandCheckApp = "check-app"
+const commandQuery = "query"
+const commandAdvise = "advise"
+const commandExplain = "explain"
+const commandConfig = "config"
+const commandConfigShow = "config show"
+
+// Options is the configuration of a run, as parsed from the command line by
+// [ParseOptions].
+type Options struct {
+	// Command is the name of the subcommand that was invoked. When no
+	// subcommand is named, it is `generate`.
+	Command string
+	LintDir string
+	GapsDir string
+
+	// CheckAppFile is the lockfile of the application given to the
+	// `check-app` subcommand.
+	CheckAppFile string
+
+	// Query is the `name` or `name@version` given to the `query` subcommand.
+	Query string
+
+	// AdviseFile is the lockfile, or `package.json`, of the application given
+	// to the `advise` subcommand.
+	AdviseFile string
+
+	// Explain is the name of the module given to the `explain` subcommand.
+	Explain string
+
+	// InstalledAgentVersion is the set of installed agent versions given to
+	// the `advise` subcommand. It is separate from AgentVersion, which
+	// filters the generated report, so that configuring one does not affect
+	// the other.
+	InstalledAgentVersion string
+
+	AgentVersion       string
+	AiCompatJsonFile   string
+	ShowDetails        bool
+	Exclude            string
+	Include            string
+	LogFile            string
+	LogFormat          string
+	NoExternals        bool
+	NodeMatrix         string
+	Recursive          bool
+	OutputFormat       string
+	ReplaceInFile      string
+	RepoDir            string
+	ReportFile         string
+	ShowTestedVersions bool
+	SkipMissingFiles   bool
+	Strict             bool
+	TestDir            string
+	Verbose            bool
+
+	StartMarker string
+	EndMarker   string
 
-var flags = appFlags{}
+	// ConfigFile is the path of the config file the options were read from.
+	ConfigFile string
+
+	// Sources maps the name of each option, see [optionBindings], to where
+	// its value came from: a flag, an environment variable, the config file,
+	// or the default.
+	Sources map[string]string
+}
 
 var usageText = heredoc.Doc(`
 	This tool is used to generate a document detailing the modules that
@@ -28,25 +90,172 @@ var usageText = heredoc.Doc(`
 	The following flags are supported:
 `)
 
-func createAndParseFlags(args []string) error {
+// ParseOptions parses the command line arguments into the options of a run.
+// Each option is taken from, in order of precedence, its flag, its
+// environment variable, the config file, or its default. Flags that apply to
+// every subcommand are defined on the parser itself, while flags that only
+// apply to report generation are defined on the `generate` subcommand. When
+// the arguments do not name a subcommand, `generate` is assumed, so that
+// invocations without a subcommand continue to generate the report.
+func ParseOptions(args []string) (Options, error) {
+	// The flags are parsed twice: once to find the config file, and again
+	// over the options read from the config file and the environment, so that
+	// the flags take precedence over both.
+	located, _, err := parseFlags(args, defaultOptions())
+	if err != nil {
+		return located, err
+	}
 
+	opts := defaultOptions()
+	err = applyConfigFile(&opts, located.ConfigFile)
+	if err != nil {
+		return opts, err
+	}
+	err = applyEnvironment(&opts)
+	if err != nil {
+		return opts, err
+	}
+
+	opts, setFlags, err := parseFlags(args, opts)
+	if err != nil {
+		return opts, err
+	}
+	for _, name := range setFlags {
+		if _, found := opts.Sources[name]; found == true {
+			opts.Sources[name] = optionSourceFlag
+		}
+	}
+	return opts, nil
+}
+
+// parseFlags parses the command line arguments over the given options. The
+// names of the flags that were given are returned along with the options.
+func parseFlags(args []string, opts Options) (Options, []string, error) {
+	parser := newParser(&opts)
+	err := parser.ParseArgs(withDefaultCommand(args, parser))
+	if err != nil {
+		return opts, nil, err
+	}
+
+	setFlags := make([]string, 0)
+	for _, cmd := range append([]*flaggy.Subcommand{&parser.Subcommand}, parser.Subcommands...) {
+		if cmd != &parser.Subcommand && cmd.Used == false {
+			continue
+		}
+		for _, value := range cmd.ParsedValues {
+			key, _, _ := strings.Cut(value.Key, "=")
+			for _, f := range cmd.Flags {
+				// Both names are returned, as the flags are registered with the
+				// long name in the position flaggy names the short name.
+				if key != "" && (f.LongName == key || f.ShortName == key) {
+					setFlags = append(setFlags, f.ShortName, f.LongName)
+				}
+			}
+		}
+	}
+
+	for _, cmd := range parser.Subcommands {
+		if cmd.Used == false {
+			continue
+		}
+		opts.Command = cmd.Name
+		if cmd.Name == commandConfig && slices.ContainsFunc(cmd.Subcommands, isUsed) == true {
+			opts.Command = commandConfigShow
+		}
+	}
+	return opts, setFlags, nil
+}
+
+// isUsed determines if the subcommand was named by the arguments.
+func isUsed(cmd *flaggy.Subcommand) bool {
+	return cmd.Used
+}
+
+// newParser creates the command line parser, binding the flags to the
+// options.
+func newParser(opts *Options) *flaggy.Parser {
 	parser := flaggy.NewParser("nrversions")
 	parser.ShowHelpOnUnexpected = false
 	parser.Description = usageText
 
 	parser.String(
-		&flags.aiCompatJsonFile,
-		"ai-compat-json",
-		"a",
+		&opts.ConfigFile,
+		"config",
+		"c",
 		heredoc.Doc(`
-			Path to the ai-compat.json file that describes the AI Monitoring
-			compatibility of the agent. The default is to use the JSON file included
-			in the mainline agent repository.
+			Path to a YAML config file that sets options by their flag names, e.g.
+			"repo-dir: ../node-newrelic". The default is ".nrversions.yaml" in the
+			working directory, when it exists. Flags take precedence over
+			environment variables, which take precedence over the config file.
+		`),
+	)
+
+	generateCmd := flaggy.NewSubcommand(commandGenerate)
+	generateCmd.Description = "Generate the compatibility report. This is the default subcommand."
+
+	parser.String(
+		&opts.Exclude,
+		"exclude",
+		"e",
+		heredoc.Doc(`
+			A comma separated list of glob patterns, e.g. "aws-*,**/legacy". Test
+			directories whose path, relative to a versioned tests directory, matches
+			a pattern are skipped along with everything within them. A "**" segment
+			matches any number of directories.
+		`),
+	)
+
+	parser.String(
+		&opts.OutputFormat,
+		"format",
+		"f",
+		heredoc.Doc(`
+			Specify the format of the generated report. Supported values are
+			"markdown", "json", and "terminal". The default is "markdown". The
+			"terminal" format renders the compatibility table to fit the width of
+			the terminal, highlights modules whose latest version is not tested or
+			whose minimum supported version is very old, and is shown through the
+			pager named by $PAGER, or "less", when stdout is a terminal.
+		`),
+	)
+
+	parser.String(
+		&opts.Include,
+		"include",
+		"i",
+		heredoc.Doc(`
+			A comma separated list of glob patterns. When given, only test
+			directories whose path, relative to a versioned tests directory, matches
+			a pattern are processed. A "**" segment matches any number of
+			directories.
+		`),
+	)
+
+	parser.String(
+		&opts.LogFile,
+		"log-file",
+		"l",
+		heredoc.Doc(`
+			Path to a file that logs are appended to, instead of writing them to
+			stderr. The file is created if it does not exist. The summary of the
+			errors of a run is still written to stderr.
+		`),
+	)
+
+	parser.String(
+		&opts.LogFormat,
+		"log-format",
+		"L",
+		heredoc.Doc(`
+			Specify the format of the logs. Supported values are "text", "json", and
+			"pretty". The default is "pretty". The "text" and "json" formats write
+			one record per line, with the package, repo, phase, and duration of the
+			work being logged as separate attributes.
 		`),
 	)
 
 	parser.Bool(
-		&flags.noExternals,
+		&opts.NoExternals,
 		"no-externals",
 		"n",
 		heredoc.Doc(`
@@ -57,24 +266,20 @@ func createAndParseFlags(args []string) error {
 		`),
 	)
 
-	parser.String(
-		&flags.replaceInFile,
-		"replace-in-file",
-		"R",
+	parser.Bool(
+		&opts.Recursive,
+		"recursive",
+		"D",
 		heredoc.Doc(`
-			Specify a target file in which the results will be written. Normally,
-			the result is written to stdout. When this flaggy is given, the result
-			will be written to the specified file. The generated text will replace
-			all text in the file between two marker lines. The markers can be defined
-			through environment variables: START_MARKER and END_MARKER. Default values
-			are "{/* begin: compat-table */}"
-			and "{/* end: compat-table */}".
-		`,
-		),
+			Search every level of the versioned tests directories for versioned
+			test package.json files, instead of only the immediate subdirectories.
+			Directories without a package.json, and package.json files that do not
+			describe versioned tests, are skipped.
+		`),
 	)
 
 	parser.String(
-		&flags.repoDir,
+		&opts.RepoDir,
 		"repo-dir",
 		"r",
 		heredoc.Doc(`
@@ -85,43 +290,266 @@ func createAndParseFlags(args []string) error {
 	)
 
 	parser.String(
-		&flags.testDir,
+		&opts.ReportFile,
+		"report",
+		"p",
+		heredoc.Doc(`
+			Path to a report previously generated with "--format json". Subcommands
+			that inspect the compatibility data, i.e. "check-app", "query", and
+			"advise", read the data from the report instead of cloning and
+			processing the repositories.
+		`),
+	)
+
+	parser.Bool(
+		&opts.SkipMissingFiles,
+		"skip-missing-files",
+		"M",
+		heredoc.Doc(`
+			Exclude versioned test blocks that list test files that do not exist
+			from the computation of supported versions. Such blocks are skipped by
+			the versioned test runner, so they do not demonstrate support.
+		`),
+	)
+
+	parser.String(
+		&opts.TestDir,
 		"test-dir",
 		"t",
 		heredoc.Doc(`
       Specify the test directory to parse the package.json files.
       If not provided, it will default to 'test/versioned'. This applies to
-			the repo provided by the --repo-dir flaggy.
+			the repo provided by the --repo-dir flaggy. Multiple directories may be
+			given as a comma separated list.
     `),
 	)
 
 	parser.Bool(
-		&flags.verbose,
+		&opts.Verbose,
 		"verbose",
 		"v",
 		heredoc.Doc(`
 			Enable verbose output. As the data is being loaded and parsed various
-			logs will be written to stderr that should give indicators of what
-			is happening.
+			logs will be written to stderr, or the --log-file, that should give
+			indicators of what is happening.
 		`),
 	)
 
-	readEnvironment()
-	return parser.ParseArgs(args)
+	generateCmd.String(
+		&opts.AgentVersion,
+		"agent-version",
+		"A",
+		heredoc.Doc(`
+			Generate the report as of a release of the agent, e.g. "11.5.0". Modules
+			first instrumented by a later release are omitted from the table, and
+			listed in a separate section of the Markdown report. Modules that are
+			instrumented by a separately installed package are kept unless a version
+			of that package is also given, e.g.
+			"11.5.0,@newrelic/apollo-server-plugin@3.0.0".
+		`),
+	)
+
+	generateCmd.String(
+		&opts.AiCompatJsonFile,
+		"ai-compat-json",
+		"a",
+		heredoc.Doc(`
+			Path to the ai-compat.json file that describes the AI Monitoring
+			compatibility of the agent. The default is to use the JSON file included
+			in the mainline agent repository.
+		`),
+	)
+
+	generateCmd.Bool(
+		&opts.ShowDetails,
+		"details",
+		"d",
+		heredoc.Doc(`
+			Include a section in the Markdown report that details, for each module,
+			the versioned test blocks that cover it: the tested range, the number of
+			sampled versions, the Node.js engine, and the test files that are run.
+			Modules whose versioned tests disagree on the minimum supported version
+			or the minimum agent version are listed in a conflicts section.
+		`),
+	)
+
+	generateCmd.String(
+		&opts.NodeMatrix,
+		"node-matrix",
+		"N",
+		heredoc.Doc(`
+			Include a section in the Markdown report that shows which Node.js major
+			versions each tested range of a module is verified under. The value is
+			a comma separated list of Node.js major versions, e.g. "20,22,24".
+		`),
+	)
+
+	generateCmd.String(
+		&opts.ReplaceInFile,
+		"replace-in-file",
+		"R",
+		heredoc.Doc(`
+			Specify a target file in which the results will be written. Normally,
+			the result is written to stdout. When this flaggy is given, the result
+			will be written to the specified file. The generated text will replace
+			all text in the file between two marker lines. The markers can be defined
+			through the "start-marker" and "end-marker" options of the config file, or
+			the environment variables NRVERSIONS_START_MARKER and
+			NRVERSIONS_END_MARKER. Default values are "{/* begin: compat-table */}"
+			and "{/* end: compat-table */}".
+		`,
+		),
+	)
+
+	generateCmd.Bool(
+		&opts.Strict,
+		"strict",
+		"S",
+		heredoc.Doc(`
+			Exit with a non-zero code when any data could not be processed. Without
+			this flag, errors encountered while cloning, parsing, or querying the
+			registry are summarized at the end of the run, but the document is still
+			generated and the exit code is 0.
+		`),
+	)
+
+	generateCmd.Bool(
+		&opts.ShowTestedVersions,
+		"tested-versions",
+		"T",
+		heredoc.Doc(`
+			Include a section in the Markdown report that lists the versions of each
+			module selected by the versioned test runner, and the number of
+			published versions within the supported ranges that are not tested.
+		`),
+	)
+	parser.AttachSubcommand(generateCmd, 1)
+
+	lintCmd := flaggy.NewSubcommand(commandLint)
+	lintCmd.Description = "Validate the versioned test package.json files in a directory."
+	lintCmd.AddPositionalValue(
+		&opts.LintDir,
+		"dir",
+		1,
+		true,
+		"The versioned tests directory to validate.",
+	)
+	parser.AttachSubcommand(lintCmd, 1)
+
+	gapsCmd := flaggy.NewSubcommand(commandGaps)
+	gapsCmd.Description = "Report targets that are not tested, and tested modules that are not targets."
+	gapsCmd.AddPositionalValue(
+		&opts.GapsDir,
+		"dir",
+		1,
+		true,
+		"The versioned tests directory to inspect.",
+	)
+	parser.AttachSubcommand(gapsCmd, 1)
+
+	checkAppCmd := flaggy.NewSubcommand(commandCheckApp)
+	checkAppCmd.Description = "Report the support status of the instrumented modules installed in an application."
+	checkAppCmd.AddPositionalValue(
+		&opts.CheckAppFile,
+		"lockfile",
+		1,
+		true,
+		"The lockfile, or CycloneDX or SPDX JSON SBOM, of the application.",
+	)
+	parser.AttachSubcommand(checkAppCmd, 1)
+
+	queryCmd := flaggy.NewSubcommand(commandQuery)
+	queryCmd.Description = "Show the compatibility data of a single module, and whether a version of it is supported."
+	queryCmd.AddPositionalValue(
+		&opts.Query,
+		"package",
+		1,
+		true,
+		`The module to query, optionally with a version, e.g. "koa" or "koa@2.14.1".`,
+	)
+	parser.AttachSubcommand(queryCmd, 1)
+
+	adviseCmd := flaggy.NewSubcommand(commandAdvise)
+	adviseCmd.Description = "Recommend the agent upgrades needed to instrument the modules installed in an application."
+	adviseCmd.AddPositionalValue(
+		&opts.AdviseFile,
+		"manifest",
+		1,
+		true,
+		"The lockfile, SBOM, or package.json of the application.",
+	)
+	adviseCmd.String(
+		&opts.InstalledAgentVersion,
+		"installed-agent-version",
+		"A",
+		heredoc.Doc(`
+			The installed versions of the agent, and of any separately installed
+			instrumentation packages, e.g.
+			"11.5.0,@newrelic/apollo-server-plugin@3.0.0". The versions override the
+			ones installed in the application.
+		`),
+	)
+	parser.AttachSubcommand(adviseCmd, 1)
+
+	explainCmd := flaggy.NewSubcommand(commandExplain)
+	explainCmd.Description = "Show how each field of the compatibility data of a single module was derived."
+	explainCmd.AddPositionalValue(
+		&opts.Explain,
+		"package",
+		1,
+		true,
+		"The module to explain.",
+	)
+	parser.AttachSubcommand(explainCmd, 1)
+
+	configCmd := flaggy.NewSubcommand(commandConfig)
+	configCmd.Description = "Inspect the configuration."
+	configShowCmd := flaggy.NewSubcommand("show")
+	configShowCmd.Description = "Print the effective configuration, and where each value came from."
+	configCmd.AttachSubcommand(configShowCmd, 1)
+	parser.AttachSubcommand(configCmd, 1)
+
+	return parser
 }
 
-func readEnvironment() {
-	marker, envIsSet := os.LookupEnv("START_MARKER")
-	if envIsSet == true {
-		flags.startMarker = marker
-	} else {
-		flags.startMarker = "{/* begin: compat-table */}"
+// withDefaultCommand prepends the `generate` subcommand to the arguments when
+// they do not name a subcommand. A request for help without a subcommand is
+// left alone, so that the help of the parser, which lists the subcommands, is
+// shown.
+func withDefaultCommand(args []string, parser *flaggy.Parser) []string {
+	// The values of flags that take one must be skipped in order to find the
+	// first positional argument.
+	valueFlags := make([]string, 0)
+	for _, cmd := range append([]*flaggy.Subcommand{&parser.Subcommand}, parser.Subcommands...) {
+		for _, f := range cmd.Flags {
+			if _, isBool := f.AssignmentVar.(*bool); isBool == false {
+				valueFlags = append(valueFlags, f.LongName, f.ShortName)
+			}
+		}
 	}
 
-	marker, envIsSet = os.LookupEnv("END_MARKER")
-	if envIsSet == true {
-		flags.endMarker = marker
-	} else {
-		flags.endMarker = "{/* end: compat-table */}"
+	for i := 0; i < len(args); i += 1 {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, "-") == false {
+			isCommand := slices.ContainsFunc(parser.Subcommands, func(cmd *flaggy.Subcommand) bool {
+				return cmd.Name == arg
+			})
+			if isCommand == true {
+				return args
+			}
+			break
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if name == "h" || name == "help" {
+			return args
+		}
+		if strings.Contains(name, "=") == false && slices.Contains(valueFlags, name) == true {
+			i += 1
+		}
 	}
+	return append([]string{commandGenerate}, args...)
 }
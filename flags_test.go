@@ -2,25 +2,96 @@ package main
 
 import (
 	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
 	"testing"
 )
 
-func Test_createAndParseFlags(t *testing.T) {
+func Test_ParseOptions(t *testing.T) {
 	t.Run("defaults are as expected", func(t *testing.T) {
-		err := createAndParseFlags([]string{"ignored"})
-		expected := appFlags{
-			aiCompatJsonFile: "",
-			noExternals:      false,
-			startMarker:      "{/* begin: compat-table */}",
-			endMarker:        "{/* end: compat-table */}",
+		t.Chdir(t.TempDir())
+		clearConfigEnvironment(t)
+
+		opts, err := ParseOptions([]string{"ignored"})
+		expected := Options{
+			Command:          commandGenerate,
+			AiCompatJsonFile: "",
+			LogFormat:        "pretty",
+			NoExternals:      false,
+			OutputFormat:     "markdown",
+			StartMarker:      "{/* begin: compat-table */}",
+			EndMarker:        "{/* end: compat-table */}",
+			Sources:          defaultOptions().Sources,
 		}
 		assert.Nil(t, err)
-		assert.Equal(t, expected, flags)
+		assert.Equal(t, expected, opts)
 	})
 
 	t.Run("no-externals", func(t *testing.T) {
-		err := createAndParseFlags([]string{"--no-externals"})
+		opts, err := ParseOptions([]string{"--no-externals"})
+		assert.Nil(t, err)
+		assert.Equal(t, true, opts.NoExternals)
+	})
+
+	t.Run("log options", func(t *testing.T) {
+		opts, err := ParseOptions([]string{"lint", "--log-format", "json", "--log-file", "nrversions.log", "test/versioned"})
 		assert.Nil(t, err)
-		assert.Equal(t, true, flags.noExternals)
+		assert.Equal(t, "json", opts.LogFormat)
+		assert.Equal(t, "nrversions.log", opts.LogFile)
+		assert.Equal(t, optionSourceFlag, opts.Sources["log-format"])
 	})
+
+	t.Run("generates by default", func(t *testing.T) {
+		opts, err := ParseOptions([]string{"--repo-dir", "lint", "--details", "-R", "compat.md"})
+		assert.Nil(t, err)
+		assert.Equal(t, commandGenerate, opts.Command)
+		assert.Equal(t, "lint", opts.RepoDir)
+		assert.Equal(t, true, opts.ShowDetails)
+		assert.Equal(t, "compat.md", opts.ReplaceInFile)
+	})
+
+	t.Run("generate is explicit", func(t *testing.T) {
+		opts, err := ParseOptions([]string{"generate", "--strict", "--no-externals"})
+		assert.Nil(t, err)
+		assert.Equal(t, commandGenerate, opts.Command)
+		assert.Equal(t, true, opts.Strict)
+		assert.Equal(t, true, opts.NoExternals)
+	})
+
+	t.Run("global flags apply to subcommands", func(t *testing.T) {
+		opts, err := ParseOptions([]string{"--format", "json", "query", "koa@2.14.1", "--report", "compat.json"})
+		assert.Nil(t, err)
+		assert.Equal(t, commandQuery, opts.Command)
+		assert.Equal(t, "koa@2.14.1", opts.Query)
+		assert.Equal(t, "json", opts.OutputFormat)
+		assert.Equal(t, "compat.json", opts.ReportFile)
+	})
+
+	t.Run("subcommand flags", func(t *testing.T) {
+		opts, err := ParseOptions([]string{"advise", "package.json", "--installed-agent-version", "9.0.0"})
+		assert.Nil(t, err)
+		assert.Equal(t, commandAdvise, opts.Command)
+		assert.Equal(t, "package.json", opts.AdviseFile)
+		assert.Equal(t, "9.0.0", opts.InstalledAgentVersion)
+		assert.Equal(t, "", opts.AgentVersion)
+
+		opts, err = ParseOptions([]string{"--installed-agent-version", "9.0.0", "advise", "package.json"})
+		assert.Nil(t, err)
+		assert.Equal(t, commandAdvise, opts.Command)
+		assert.Equal(t, "package.json", opts.AdviseFile)
+		assert.Equal(t, "9.0.0", opts.InstalledAgentVersion)
+	})
+}
+
+// clearConfigEnvironment unsets, for the duration of the test, every
+// environment variable that sets an option.
+func clearConfigEnvironment(t *testing.T) {
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(name, configEnvPrefix) == false && name != "START_MARKER" && name != "END_MARKER" {
+			continue
+		}
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
 }
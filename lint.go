@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"blitznote.com/src/semver/v3"
+)
+
+var ErrLintFailed = errors.New("lint found problems")
+
+// LintDiagnostic describes a single problem found in a versioned test
+// `package.json` file.
+type LintDiagnostic struct {
+	// File is the path to the file that contains the problem.
+	File string
+
+	// Line is the 1-based line number of the problem within the file. A value
+	// of `0` indicates the problem applies to the file as a whole.
+	Line int
+
+	Message string
+}
+
+func (ld LintDiagnostic) String() string {
+	if ld.Line == 0 {
+		return fmt.Sprintf("%s: %s", ld.File, ld.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", ld.File, ld.Line, ld.Message)
+}
+
+// runLint validates every versioned test `package.json` that is reachable
+// from the given directory and writes the found problems to the writer. An
+// error wrapping [ErrLintFailed] is returned if any problems were found.
+func runLint(dir string, opts discoveryOptions, writer io.Writer) error {
+	diagnostics := lintTestDir(dir, opts)
+	for _, diagnostic := range diagnostics {
+		io.WriteString(writer, diagnostic.String()+"\n")
+	}
+
+	if len(diagnostics) > 0 {
+		return fmt.Errorf("%w: %d problem(s)", ErrLintFailed, len(diagnostics))
+	}
+	return nil
+}
+
+// lintTestDir iterates a versioned tests directory and collects the problems
+// found in each `package.json` file.
+func lintTestDir(dir string, opts discoveryOptions) []LintDiagnostic {
+	results := make([]LintDiagnostic, 0)
+
+	iterChan := make(chan dirIterChan)
+	go iterateTestDir(dir, opts, iterChan)
+	for result := range iterChan {
+		if result.err != nil {
+			results = append(results, lintIterationError(result))
+			continue
+		}
+
+		data, err := os.ReadFile(result.path)
+		if err != nil {
+			results = append(results, LintDiagnostic{File: result.path, Message: err.Error()})
+			continue
+		}
+		results = append(results, lintPackage(result.path, data, result.pkg)...)
+		results = append(results, lintTestFiles(result.path, data, result.pkg)...)
+	}
+
+	slices.SortStableFunc(results, func(a LintDiagnostic, b LintDiagnostic) int {
+		if a.File != b.File {
+			return strings.Compare(a.File, b.File)
+		}
+		return a.Line - b.Line
+	})
+	return results
+}
+
+// lintIterationError converts an error encountered while reading a versioned
+// test directory into a diagnostic. Syntax and type errors are located
+// within the file when possible.
+func lintIterationError(result dirIterChan) LintDiagnostic {
+	diagnostic := LintDiagnostic{File: result.path, Message: result.err.Error()}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var offset int64 = -1
+	if errors.As(result.err, &syntaxErr) {
+		offset = syntaxErr.Offset
+		diagnostic.Message = "invalid JSON: " + syntaxErr.Error()
+	} else if errors.As(result.err, &typeErr) {
+		offset = typeErr.Offset
+		diagnostic.Message = "invalid JSON: " + typeErr.Error()
+	}
+
+	if offset >= 0 {
+		data, err := os.ReadFile(result.path)
+		if err == nil {
+			diagnostic.Line = offsetToLine(data, offset)
+		}
+	}
+
+	return diagnostic
+}
+
+// lintPackage validates a parsed versioned test `package.json`. The raw data
+// of the file is used to locate the problems within the file.
+func lintPackage(file string, data []byte, pkg *VersionedTestPackageJson) []LintDiagnostic {
+	results := make([]LintDiagnostic, 0)
+	positions, _ := jsonPositions(data)
+	report := func(pointer string, format string, args ...any) {
+		results = append(results, LintDiagnostic{
+			File:    file,
+			Line:    lineForPointer(data, positions, pointer),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if len(pkg.Targets) == 0 {
+		report("/targets", "no targets are defined")
+	}
+	if len(pkg.Tests) == 0 {
+		report("/tests", "no tests are defined")
+	}
+
+	seenTargets := make(map[string]bool)
+	for i, target := range pkg.Targets {
+		pointer := fmt.Sprintf("/targets/%d", i)
+
+		if target.Name == "" {
+			report(pointer, "target is missing a name")
+		} else if seenTargets[target.Name] == true {
+			report(pointer, "duplicate target `%s`", target.Name)
+		}
+		seenTargets[target.Name] = true
+
+		if target.MinAgentVersion == "" {
+			report(pointer, "target `%s` is missing minAgentVersion", target.Name)
+		} else {
+			_, version := splitAgentVersion(target.MinAgentVersion)
+			_, err := semver.NewVersion([]byte(version))
+			if err != nil {
+				report(
+					pointer+"/minAgentVersion",
+					"target `%s` has invalid minAgentVersion `%s`",
+					target.Name,
+					target.MinAgentVersion,
+				)
+			}
+		}
+
+		if target.MinSupported != "" {
+			_, err := semver.NewRange([]byte(target.MinSupported))
+			if err != nil {
+				report(
+					pointer+"/minSupported",
+					"target `%s` has invalid minSupported `%s`",
+					target.Name,
+					target.MinSupported,
+				)
+			} else if mismatch := checkMinSupported(target, pkg.Tests); mismatch != "" {
+				report(pointer+"/minSupported", "target `%s`: %s", target.Name, mismatch)
+			}
+			continue
+		}
+
+		if target.Name != "" && isTargetTested(target, pkg.Tests) == false {
+			report(pointer, "target `%s` is not a dependency of any test", target.Name)
+		}
+	}
+
+	for i, test := range pkg.Tests {
+		for name, dep := range test.Dependencies {
+			spec := parseDependencySpec(name, dep.Versions)
+			if spec.Package == "" {
+				report(
+					fmt.Sprintf("/tests/%d/dependencies/%s", i, escapeJsonPointer(name)),
+					"dependency `%s` has invalid alias `%s`",
+					name,
+					dep.Versions,
+				)
+				continue
+			}
+			if spec.Kind != specKindRange {
+				continue
+			}
+
+			_, err := parseRangeExpression(spec.Range)
+			if err != nil {
+				report(
+					fmt.Sprintf("/tests/%d/dependencies/%s", i, escapeJsonPointer(name)),
+					"dependency `%s` has invalid range `%s`: %s",
+					name,
+					dep.Versions,
+					err,
+				)
+			}
+		}
+	}
+
+	return results
+}
+
+// lintTestFiles verifies that every test file listed by the test blocks of a
+// versioned test `package.json` exists, and that every tested target is
+// covered by at least one existing test file.
+func lintTestFiles(file string, data []byte, pkg *VersionedTestPackageJson) []LintDiagnostic {
+	results := make([]LintDiagnostic, 0)
+	positions, _ := jsonPositions(data)
+	pkgDir := filepath.Dir(file)
+
+	for i, test := range pkg.Tests {
+		for _, missing := range missingTestFiles(pkgDir, test) {
+			j := slices.Index(test.Files, missing)
+			results = append(results, LintDiagnostic{
+				File:    file,
+				Line:    lineForPointer(data, positions, fmt.Sprintf("/tests/%d/files/%d", i, j)),
+				Message: fmt.Sprintf("test file `%s` does not exist", missing),
+			})
+		}
+	}
+
+	for i, target := range pkg.Targets {
+		if isTargetTested(target, pkg.Tests) == false || hasExistingTestFile(pkgDir, target, pkg.Tests) == true {
+			continue
+		}
+		results = append(results, LintDiagnostic{
+			File:    file,
+			Line:    lineForPointer(data, positions, fmt.Sprintf("/targets/%d", i)),
+			Message: fmt.Sprintf("target `%s` is not covered by any existing test file", target.Name),
+		})
+	}
+
+	return results
+}
+
+// isTargetTested determines if the target is a dependency of any test.
+func isTargetTested(target Target, tests []TestDescription) bool {
+	return slices.ContainsFunc(tests, func(test TestDescription) bool {
+		return dependsOn(test, target.Name)
+	})
+}
+
+// jsonPositions maps the JSON pointer of every value within a document to the
+// byte offset at which it appears. For object members, the offset is the end
+// of the member's key.
+func jsonPositions(data []byte) (map[string]int64, error) {
+	positions := make(map[string]int64)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	err := walkJsonValue(decoder, "", positions, true)
+	return positions, err
+}
+
+// walkJsonValue reads a single value, and all of its children, from the
+// decoder while recording the positions of the values.
+func walkJsonValue(decoder *json.Decoder, pointer string, positions map[string]int64, record bool) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if record == true {
+		positions[pointer] = decoder.InputOffset()
+	}
+
+	delim, isDelim := token.(json.Delim)
+	if isDelim == false {
+		return nil
+	}
+
+	switch delim {
+	case '{':
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			memberPointer := pointer + "/" + escapeJsonPointer(fmt.Sprintf("%v", keyToken))
+			positions[memberPointer] = decoder.InputOffset()
+			err = walkJsonValue(decoder, memberPointer, positions, false)
+			if err != nil {
+				return err
+			}
+		}
+	case '[':
+		for i := 0; decoder.More(); i += 1 {
+			err = walkJsonValue(decoder, fmt.Sprintf("%s/%d", pointer, i), positions, true)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Consume the closing delimiter.
+	_, err = decoder.Token()
+	return err
+}
+
+// escapeJsonPointer escapes a key for inclusion in a JSON pointer as
+// described by RFC 6901.
+func escapeJsonPointer(key string) string {
+	key = strings.ReplaceAll(key, "~", "~0")
+	return strings.ReplaceAll(key, "/", "~1")
+}
+
+// lineForPointer finds the line on which the value identified by the pointer
+// appears. If the pointer is not present in the document, the closest parent
+// that is present is used.
+func lineForPointer(data []byte, positions map[string]int64, pointer string) int {
+	for {
+		if offset, found := positions[pointer]; found == true {
+			return offsetToLine(data, offset)
+		}
+		idx := strings.LastIndex(pointer, "/")
+		if idx == -1 {
+			return 0
+		}
+		pointer = pointer[0:idx]
+	}
+}
+
+// offsetToLine converts a byte offset within the data to a 1-based line
+// number.
+func offsetToLine(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 1 {
+		return 1
+	}
+	// The offset reported by the decoder is the position just after the
+	// token, so the token itself ends at the previous byte.
+	return bytes.Count(data[0:offset-1], []byte("\n")) + 1
+}
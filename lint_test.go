@@ -0,0 +1,85 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_runLint(t *testing.T) {
+	t.Run("reports no problems for a good directory", func(t *testing.T) {
+		builder := strings.Builder{}
+		err := runLint("testdata/lint/good", discoveryOptions{}, &builder)
+		assert.Nil(t, err)
+		assert.Empty(t, builder.String())
+	})
+
+	t.Run("reports problems with locations", func(t *testing.T) {
+		builder := strings.Builder{}
+		err := runLint("testdata/lint", discoveryOptions{}, &builder)
+		assert.ErrorIs(t, err, ErrLintFailed)
+
+		badFile := filepath.Join("testdata", "lint", "bad", "package.json")
+		brokenFile := filepath.Join("testdata", "lint", "broken", "package.json")
+		emptyFile := filepath.Join("testdata", "lint", "empty", "package.json")
+		missingFile := filepath.Join("testdata", "lint", "missing-files", "package.json")
+		expected := []string{
+			badFile + ":5: duplicate target `foo`",
+			badFile + ":6: target `bar` is missing minAgentVersion",
+			badFile + ":7: target `baz` has invalid minAgentVersion `@newrelic/baz@one`",
+			badFile + ":8: target `@scope/qux` is not a dependency of any test",
+			badFile + ":14: dependency `bar` has invalid range `^bogus`: failed to parse version string `^bogus`",
+			brokenFile + ":10: invalid JSON: invalid character ']' looking for beginning of value",
+			emptyFile + ":3: target `foo` is not a dependency of any test",
+			emptyFile + ":4: no tests are defined",
+			missingFile + ":5: target `bar` is not covered by any existing test file",
+			missingFile + ":12: test file `renamed.test.js` does not exist",
+			missingFile + ":18: test file `bar.test.js` does not exist",
+		}
+		found := strings.Split(strings.TrimSpace(builder.String()), "\n")
+		require.Equal(t, len(expected), len(found))
+		for i, line := range expected {
+			assert.True(t, strings.HasPrefix(found[i], line), found[i])
+		}
+	})
+}
+
+func Test_jsonPositions(t *testing.T) {
+	data := []byte("{\n  \"a\": [\n    1,\n    {\"b/c\": true}\n  ]\n}")
+	positions, err := jsonPositions(data)
+	require.Nil(t, err)
+	assert.Equal(t, 1, lineForPointer(data, positions, ""))
+	assert.Equal(t, 2, lineForPointer(data, positions, "/a"))
+	assert.Equal(t, 3, lineForPointer(data, positions, "/a/0"))
+	assert.Equal(t, 4, lineForPointer(data, positions, "/a/1/b~1c"))
+	assert.Equal(t, 4, lineForPointer(data, positions, "/a/1/missing"))
+}
+
+func Test_splitAgentVersion(t *testing.T) {
+	pkg, version := splitAgentVersion("1.2.3")
+	assert.Equal(t, "newrelic", pkg)
+	assert.Equal(t, "1.2.3", version)
+
+	pkg, version = splitAgentVersion("@newrelic/foo@1.2.3")
+	assert.Equal(t, "@newrelic/foo", pkg)
+	assert.Equal(t, "1.2.3", version)
+}
+
+func Test_lintPackage(t *testing.T) {
+	t.Run("reports minSupported mismatches", func(t *testing.T) {
+		file := "testdata/min-supported-mismatch.json"
+		data, err := os.ReadFile(file)
+		require.Nil(t, err)
+		pkg := readJsonFile(t, file)
+
+		found := lintPackage(file, data, &pkg)
+		require.Equal(t, 2, len(found))
+		assert.Equal(t, 4, found[0].Line)
+		assert.Contains(t, found[0].Message, "target `foo`: declared minSupported `1.0.0` is lower")
+		assert.Equal(t, 5, found[1].Line)
+		assert.Contains(t, found[1].Message, "target `bar`: declared minSupported `3.0.0` is higher")
+	})
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"blitznote.com/src/semver/v3"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnsupportedLockfile = errors.New("unsupported lockfile")
+
+// InstalledPackage is a package, and the version of it, that is installed
+// in an application according to its lockfile.
+type InstalledPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// packageLockJson represents the parts of a `package-lock.json`, or
+// `npm-shrinkwrap.json`, that are needed to determine the installed
+// packages. Version 1 lockfiles use the nested Dependencies, while versions
+// 2 and 3 use the flat Packages.
+type packageLockJson struct {
+	LockfileVersion int                              `json:"lockfileVersion"`
+	Packages        map[string]packageLockPackage    `json:"packages"`
+	Dependencies    map[string]packageLockDependency `json:"dependencies"`
+}
+
+type packageLockPackage struct {
+	// Name is only present when the package is installed under an alias.
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Link    bool   `json:"link"`
+}
+
+type packageLockDependency struct {
+	Version      string                           `json:"version"`
+	Dependencies map[string]packageLockDependency `json:"dependencies"`
+}
+
+// pnpmLockYaml represents the parts of a `pnpm-lock.yaml` that are needed to
+// determine the installed packages.
+type pnpmLockYaml struct {
+	LockfileVersion string         `yaml:"lockfileVersion"`
+	Packages        map[string]any `yaml:"packages"`
+}
+
+// yarnBerryEntry is an entry of a `yarn.lock` generated by Yarn 2 or later.
+type yarnBerryEntry struct {
+	Version    string `yaml:"version"`
+	Resolution string `yaml:"resolution"`
+}
+
+// readInstalledPackages reads the packages installed in an application from
+// its lockfile, or from an SBOM. The lockfile format is determined by the
+// name of the file, while any other JSON file is read as a CycloneDX or SPDX
+// document. The result is sorted by name and version, and does not contain
+// duplicates. Packages that are not installed from the registry, e.g.
+// workspaces or git dependencies, are omitted.
+func readInstalledPackages(lockfilePath string) ([]InstalledPackage, error) {
+	data, err := os.ReadFile(lockfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	var packages []InstalledPackage
+	switch filepath.Base(lockfilePath) {
+	case "package-lock.json", "npm-shrinkwrap.json":
+		packages, err = parsePackageLock(data)
+	case "yarn.lock":
+		packages, err = parseYarnLock(data)
+	case "pnpm-lock.yaml":
+		packages, err = parsePnpmLock(data)
+	default:
+		if filepath.Ext(lockfilePath) != ".json" {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedLockfile, filepath.Base(lockfilePath))
+		}
+		packages, err = parseSbom(data)
+		if errors.Is(err, ErrUnsupportedSbom) == true {
+			return nil, fmt.Errorf("%w: %s: %w", ErrUnsupportedLockfile, filepath.Base(lockfilePath), err)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse `%s`: %w", lockfilePath, err)
+	}
+
+	return sortInstalledPackages(packages), nil
+}
+
+// parsePackageLock parses a `package-lock.json` of any lockfile version.
+func parsePackageLock(data []byte) ([]InstalledPackage, error) {
+	var lock packageLockJson
+	err := json.Unmarshal(data, &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]InstalledPackage, 0)
+	if lock.LockfileVersion >= 2 && lock.Packages != nil {
+		for key, pkg := range lock.Packages {
+			idx := strings.LastIndex(key, "node_modules/")
+			if idx < 0 || pkg.Link == true {
+				// The root project, or a workspace.
+				continue
+			}
+			name := key[idx+len("node_modules/"):]
+			if pkg.Name != "" {
+				name = pkg.Name
+			}
+			results = appendInstalled(results, name, pkg.Version)
+		}
+		return results, nil
+	}
+
+	var walk func(deps map[string]packageLockDependency)
+	walk = func(deps map[string]packageLockDependency) {
+		for name, dep := range deps {
+			results = appendInstalled(results, name, dep.Version)
+			walk(dep.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return results, nil
+}
+
+// parseYarnLock parses a `yarn.lock`. Lockfiles generated by Yarn 2 or later
+// are YAML documents with a `__metadata` entry, while Yarn 1 lockfiles use a
+// bespoke format.
+func parseYarnLock(data []byte) ([]InstalledPackage, error) {
+	if bytes.Contains(data, []byte("\n__metadata:")) == true || bytes.HasPrefix(data, []byte("__metadata:")) == true {
+		return parseYarnBerryLock(data)
+	}
+	return parseYarnClassicLock(data)
+}
+
+// parseYarnClassicLock parses a `yarn.lock` generated by Yarn 1. Each entry
+// starts with an unindented line listing the specifiers it resolves, e.g.
+// `"koa@^2.0.0", koa@^2.14.0:`, and is followed by indented fields, one of
+// which is the resolved `version`.
+func parseYarnClassicLock(data []byte) ([]InstalledPackage, error) {
+	results := make([]InstalledPackage, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	name := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") == true {
+			continue
+		}
+
+		if strings.HasPrefix(line, " ") == false {
+			header := strings.TrimSuffix(trimmed, ":")
+			specifier := strings.Trim(strings.TrimSpace(strings.Split(header, ",")[0]), `"`)
+			name = yarnSpecifierPackage(specifier)
+			continue
+		}
+
+		if name == "" || strings.HasPrefix(trimmed, "version ") == false {
+			continue
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(trimmed, "version "))
+		unquoted, err := strconv.Unquote(version)
+		if err == nil {
+			version = unquoted
+		}
+		results = appendInstalled(results, name, version)
+		name = ""
+	}
+
+	return results, scanner.Err()
+}
+
+// yarnSpecifierPackage determines the name of the registry package that a
+// Yarn 1 specifier, e.g. `koa@^2.0.0` or `alias@npm:koa@^2.0.0`, installs.
+func yarnSpecifierPackage(specifier string) string {
+	name, versions := splitPackageVersion(specifier)
+	if versions == "" {
+		return name
+	}
+	return parseDependencySpec(name, versions).Package
+}
+
+// parseYarnBerryLock parses a `yarn.lock` generated by Yarn 2 or later. The
+// name of the installed package is taken from the `resolution` of each
+// entry, e.g. `koa@npm:2.14.1`, so that aliases resolve to the real package.
+func parseYarnBerryLock(data []byte) ([]InstalledPackage, error) {
+	var lock map[string]yarnBerryEntry
+	err := yaml.Unmarshal(data, &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]InstalledPackage, 0)
+	for key, entry := range lock {
+		if key == "__metadata" {
+			continue
+		}
+		idx := strings.LastIndex(entry.Resolution, "@npm:")
+		if idx <= 0 {
+			// Workspaces, patches, git, and file dependencies.
+			continue
+		}
+		results = appendInstalled(results, entry.Resolution[0:idx], entry.Version)
+	}
+	return results, nil
+}
+
+// parsePnpmLock parses a `pnpm-lock.yaml`. The installed packages are
+// determined from the keys of the `packages` map, whose format depends on the
+// lockfile version: `/koa/2.14.1` before version 6, `/koa@2.14.1` in version
+// 6, and `koa@2.14.1` from version 9. Peer dependency suffixes, e.g.
+// `(react@18.0.0)` or `_react@18.0.0`, are ignored.
+func parsePnpmLock(data []byte) ([]InstalledPackage, error) {
+	var lock pnpmLockYaml
+	err := yaml.Unmarshal(data, &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	major, _, _ := strings.Cut(lock.LockfileVersion, ".")
+	lockfileMajor, err := strconv.Atoi(major)
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized lockfileVersion `%s`", lock.LockfileVersion)
+	}
+
+	results := make([]InstalledPackage, 0)
+	for key := range lock.Packages {
+		key = strings.TrimPrefix(key, "/")
+		key, _, _ = strings.Cut(key, "(")
+
+		if lockfileMajor < 6 {
+			idx := strings.LastIndex(key, "/")
+			if idx <= 0 {
+				continue
+			}
+			version, _, _ := strings.Cut(key[idx+1:], "_")
+			results = appendInstalled(results, key[0:idx], version)
+			continue
+		}
+
+		idx := strings.LastIndex(key, "@")
+		if idx <= 0 {
+			continue
+		}
+		results = appendInstalled(results, key[0:idx], key[idx+1:])
+	}
+	return results, nil
+}
+
+// appendInstalled appends a package to the list of installed packages. npm
+// style alias versions, e.g. `npm:koa@2.14.1`, are unwrapped, and versions
+// that are not valid semver versions, e.g. git URLs, are skipped.
+func appendInstalled(packages []InstalledPackage, name string, version string) []InstalledPackage {
+	if strings.HasPrefix(version, "npm:") == true {
+		aliased := strings.TrimPrefix(version, "npm:")
+		idx := strings.LastIndex(aliased, "@")
+		if idx <= 0 {
+			return packages
+		}
+		name = aliased[0:idx]
+		version = aliased[idx+1:]
+	}
+
+	if name == "" {
+		return packages
+	}
+	_, err := semver.NewVersion([]byte(version))
+	if err != nil {
+		return packages
+	}
+	return append(packages, InstalledPackage{Name: name, Version: version})
+}
+
+// sortInstalledPackages sorts the packages by name and version, and removes
+// duplicates.
+func sortInstalledPackages(packages []InstalledPackage) []InstalledPackage {
+	slices.SortFunc(packages, func(a InstalledPackage, b InstalledPackage) int {
+		if a.Name != b.Name {
+			return strings.Compare(a.Name, b.Name)
+		}
+		switch {
+		case isVersionLower(a.Version, b.Version):
+			return -1
+		case isVersionLower(b.Version, a.Version):
+			return 1
+		default:
+			return strings.Compare(a.Version, b.Version)
+		}
+	})
+	return slices.Compact(packages)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readInstalledPackages(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		expected []InstalledPackage
+	}{
+		{
+			name: "package-lock.json v1",
+			file: "testdata/lockfiles/npm-v1/package-lock.json",
+			expected: []InstalledPackage{
+				{Name: "debug", Version: "2.6.9"},
+				{Name: "express", Version: "4.18.2"},
+				{Name: "koa", Version: "1.7.0"},
+				{Name: "koa", Version: "2.14.1"},
+			},
+		},
+		{
+			name: "package-lock.json v3",
+			file: "testdata/lockfiles/npm-v3/package-lock.json",
+			expected: []InstalledPackage{
+				{Name: "@scope/pkg", Version: "3.0.0"},
+				{Name: "debug", Version: "2.6.9"},
+				{Name: "express", Version: "4.18.2"},
+				{Name: "koa", Version: "1.7.0"},
+			},
+		},
+		{
+			name: "yarn.lock classic",
+			file: "testdata/lockfiles/yarn-classic/yarn.lock",
+			expected: []InstalledPackage{
+				{Name: "@scope/pkg", Version: "3.0.0"},
+				{Name: "debug", Version: "2.6.9"},
+				{Name: "express", Version: "4.18.2"},
+				{Name: "koa", Version: "1.7.0"},
+			},
+		},
+		{
+			name: "yarn.lock berry",
+			file: "testdata/lockfiles/yarn-berry/yarn.lock",
+			expected: []InstalledPackage{
+				{Name: "@scope/pkg", Version: "3.0.0"},
+				{Name: "express", Version: "4.18.2"},
+				{Name: "koa", Version: "1.7.0"},
+			},
+		},
+		{
+			name: "pnpm-lock.yaml v5",
+			file: "testdata/lockfiles/pnpm-v5/pnpm-lock.yaml",
+			expected: []InstalledPackage{
+				{Name: "@scope/pkg", Version: "3.0.0"},
+				{Name: "express", Version: "4.18.2"},
+			},
+		},
+		{
+			name: "pnpm-lock.yaml v6",
+			file: "testdata/lockfiles/pnpm-v6/pnpm-lock.yaml",
+			expected: []InstalledPackage{
+				{Name: "@scope/pkg", Version: "3.0.0"},
+				{Name: "express", Version: "4.18.2"},
+			},
+		},
+		{
+			name: "pnpm-lock.yaml v9",
+			file: "testdata/lockfiles/pnpm-v9/pnpm-lock.yaml",
+			expected: []InstalledPackage{
+				{Name: "@scope/pkg", Version: "3.0.0"},
+				{Name: "express", Version: "4.18.2"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			found, err := readInstalledPackages(tc.file)
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, found)
+		})
+	}
+
+	t.Run("rejects unknown lockfiles", func(t *testing.T) {
+		_, err := readInstalledPackages("testdata/check-app-report.json")
+		assert.ErrorIs(t, err, ErrUnsupportedLockfile)
+		assert.ErrorIs(t, err, ErrUnsupportedSbom)
+
+		_, err = readInstalledPackages("testdata/replace-into.input.md")
+		assert.ErrorIs(t, err, ErrUnsupportedLockfile)
+	})
+
+	t.Run("reports missing lockfiles", func(t *testing.T) {
+		_, err := readInstalledPackages("testdata/lockfiles/package-lock.json")
+		assert.ErrorContains(t, err, "failed to read lockfile")
+	})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/dusted-go/logging/prettylog"
+)
+
+const logFormatText = "text"
+const logFormatJson = "json"
+const logFormatPretty = "pretty"
+
+// Keys of the attributes that are shared by the logs of the clone, parse,
+// and registry code paths.
+const (
+	logKeyPackage  = "package"
+	logKeyRepo     = "repo"
+	logKeyPhase    = "phase"
+	logKeyDuration = "duration"
+	logKeyFile     = "file"
+	logKeyError    = "error"
+)
+
+// buildLogger creates the logger that writes to `dest` in the given format,
+// i.e. one of "text", "json", or "pretty". Debug logs are only written when
+// `verbose` is set.
+func buildLogger(verbose bool, format string, dest io.Writer) (*slog.Logger, error) {
+	level := slog.LevelInfo
+	if verbose == true {
+		level = slog.LevelDebug
+	}
+	handlerOpts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case logFormatText:
+		handler = slog.NewTextHandler(dest, handlerOpts)
+	case logFormatJson:
+		handler = slog.NewJSONHandler(dest, handlerOpts)
+	case logFormatPretty:
+		handler = prettylog.New(handlerOpts, prettylog.WithDestinationWriter(dest))
+	default:
+		return nil, fmt.Errorf("unsupported log format: %s", format)
+	}
+
+	return slog.New(handler), nil
+}
+
+// openLogFile opens the file that logs are appended to, creating it if it
+// does not exist.
+func openLogFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return file, nil
+}
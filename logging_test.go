@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_buildLogger(t *testing.T) {
+	t.Run("returns debug level logger", func(t *testing.T) {
+		logger, err := buildLogger(true, logFormatPretty, io.Discard)
+		require.Nil(t, err)
+		assert.Equal(t, true, logger.Handler().Enabled(context.TODO(), slog.LevelError))
+		assert.Equal(t, true, logger.Handler().Enabled(context.TODO(), slog.LevelDebug))
+	})
+
+	t.Run("returns standard logger", func(t *testing.T) {
+		logger, err := buildLogger(false, logFormatPretty, io.Discard)
+		require.Nil(t, err)
+		assert.Equal(t, true, logger.Handler().Enabled(context.TODO(), slog.LevelInfo))
+		assert.Equal(t, true, logger.Handler().Enabled(context.TODO(), slog.LevelError))
+		assert.Equal(t, false, logger.Handler().Enabled(context.TODO(), slog.LevelDebug))
+	})
+
+	t.Run("writes json records", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger, err := buildLogger(false, logFormatJson, buf)
+		require.Nil(t, err)
+		logger.With(logKeyPhase, phaseClone).Info("cloned repo", logKeyRepo, "agent")
+
+		var record map[string]any
+		err = json.Unmarshal(buf.Bytes(), &record)
+		require.Nil(t, err)
+		assert.Equal(t, "cloned repo", record["msg"])
+		assert.Equal(t, phaseClone, record[logKeyPhase])
+		assert.Equal(t, "agent", record[logKeyRepo])
+	})
+
+	t.Run("writes text records", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger, err := buildLogger(false, logFormatText, buf)
+		require.Nil(t, err)
+		logger.Warn("could not resolve dist-tag", logKeyPackage, "foo")
+		assert.Contains(t, buf.String(), `level=WARN msg="could not resolve dist-tag" package=foo`)
+	})
+
+	t.Run("errors for an unsupported format", func(t *testing.T) {
+		_, err := buildLogger(false, "xml", io.Discard)
+		assert.ErrorContains(t, err, "unsupported log format: xml")
+	})
+}
+
+func Test_openLogFile(t *testing.T) {
+	t.Run("appends to the file", func(t *testing.T) {
+		logPath := filepath.Join(t.TempDir(), "nrversions.log")
+		for range 2 {
+			file, err := openLogFile(logPath)
+			require.Nil(t, err)
+			logger, err := buildLogger(false, logFormatJson, file)
+			require.Nil(t, err)
+			logger.Info("done")
+			file.Close()
+		}
+
+		data, err := os.ReadFile(logPath)
+		require.Nil(t, err)
+		assert.Equal(t, 2, bytes.Count(data, []byte("\n")))
+	})
+
+	t.Run("errors for a missing directory", func(t *testing.T) {
+		_, err := openLogFile(filepath.Join(t.TempDir(), "missing", "nrversions.log"))
+		assert.ErrorContains(t, err, "failed to open log file")
+	})
+}
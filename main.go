@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/dusted-go/logging/prettylog"
 	"github.com/spf13/afero"
 	"io"
 	"log/slog"
@@ -15,6 +14,7 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	_ "embed"
 
@@ -32,11 +32,11 @@ var docPreamble string
 var agentRepo = nrRepo{
 	url:        `https://github.com/newrelic/node-newrelic.git`,
 	branch:     `main`,
-	testPath:   `test/versioned`,
+	testPaths:  []string{`test/versioned`},
 	isMainRepo: true,
 }
 var externalsRepos = []nrRepo{
-	{url: `https://github.com/newrelic/newrelic-node-apollo-server-plugin.git`, branch: `main`, testPath: `tests/versioned`},
+	{url: `https://github.com/newrelic/newrelic-node-apollo-server-plugin.git`, branch: `main`, testPaths: []string{`tests/versioned`}},
 }
 
 var columHeaders = map[string]string{
@@ -44,71 +44,148 @@ var columHeaders = map[string]string{
 	"MinSupportedVersion": `Minimum supported version`,
 	"LatestVersion":       `Latest published version`,
 	"MinAgentVersion":     `Introduced in*`,
+	"MinNodeVersion":      `Minimum Node.js`,
 }
 
 var appFS = afero.NewOsFs()
 
 func main() {
-	err := Run(os.Args[1:])
+	opts, err := ParseOptions(os.Args[1:])
 	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
 		fmt.Printf("app error: %v", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
-}
 
-func Run(args []string) error {
-	err := createAndParseFlags(args)
+	err = Run(opts)
 	if err != nil {
-		if errors.Is(err, flag.ErrHelp) {
-			return nil
-		}
-		return err
+		fmt.Printf("app error: %v", err)
+		os.Exit(exitCode(err))
 	}
+}
 
-	logger := buildLogger(flags.verbose)
+// Run executes the subcommand named by [Options.Command].
+func Run(opts Options) error {
+	discovery := discoveryOptions{
+		recursive: opts.Recursive,
+		include:   splitList(opts.Include),
+		exclude:   splitList(opts.Exclude),
+	}
 
-	var repos []nrRepo
-	if flags.repoDir != "" {
-		var testDir string
-		repoDir := flags.repoDir
-		if flags.testDir != "" {
-			testDir = flags.testDir
-		} else {
-			testDir = "test/versioned"
-		}
-		var testRepo = nrRepo{repoDir: repoDir, testPath: testDir, isMainRepo: true}
-		repos = []nrRepo{testRepo}
-	} else {
-		repos = []nrRepo{agentRepo}
+	switch opts.Command {
+	case commandLint:
+		return runLint(opts.LintDir, discovery, os.Stdout)
+	case commandGaps:
+		return runGaps(opts.GapsDir, discovery, os.Stdout)
+	case commandConfig:
+		return fmt.Errorf("missing config subcommand, e.g. \"config show\"")
+	case commandConfigShow:
+		return runConfigShow(opts, os.Stdout)
 	}
 
-	if flags.noExternals == false {
-		repos = append(repos, externalsRepos...)
+	switch opts.OutputFormat {
+	case outputFormatMarkdown, outputFormatJson, outputFormatTerminal:
+	default:
+		return fmt.Errorf("unsupported output format: %s", opts.OutputFormat)
 	}
 
-	logger.Info("cloning repositories")
-	cloneResults := cloneRepos(repos, logger)
-	logger.Info("repository cloning complete")
+	var logDest io.Writer = os.Stderr
+	if opts.LogFile != "" {
+		logFile, err := openLogFile(opts.LogFile)
+		if err != nil {
+			return err
+		}
+		defer logFile.Close()
+		logDest = logFile
+	}
+	logger, err := buildLogger(opts.Verbose, opts.LogFormat, logDest)
+	if err != nil {
+		return err
+	}
+	// The summary is written for the user rather than the log pipeline, so it
+	// goes to stderr even when the logs go to a file. Every error it lists
+	// has also been logged.
+	runErrs := &runErrors{}
+	defer runErrs.WriteSummary(os.Stderr)
+
+	processOpts := processOptions{discovery: discovery, skipMissingFiles: opts.SkipMissingFiles}
+	switch opts.Command {
+	case commandCheckApp:
+		data, err := loadReleaseData(opts, processOpts, runErrs, logger)
+		if err != nil {
+			return err
+		}
+		return runCheckApp(opts.CheckAppFile, data, opts.OutputFormat, os.Stdout)
+	case commandQuery:
+		processOpts.packageName, _ = splitPackageVersion(opts.Query)
+		data, err := loadReleaseData(opts, processOpts, runErrs, logger)
+		if err != nil {
+			return err
+		}
+		return runQuery(opts.Query, data, opts.OutputFormat, os.Stdout)
+	case commandAdvise:
+		data, err := loadReleaseData(opts, processOpts, runErrs, logger)
+		if err != nil {
+			return err
+		}
+		return runAdvise(opts.AdviseFile, opts.InstalledAgentVersion, data, opts.OutputFormat, os.Stdout)
+	case commandExplain:
+		if opts.ReportFile != "" {
+			return fmt.Errorf("explain cannot be used with --report, the derivation is not recorded in reports")
+		}
+		processOpts.packageName = opts.Explain
+		processOpts.trace = true
+		data, err := loadReleaseData(opts, processOpts, runErrs, logger)
+		if err != nil {
+			return err
+		}
+		return runExplain(opts.Explain, data, os.Stdout)
+	default:
+		return runGenerate(opts, processOpts, runErrs, logger)
+	}
+}
 
-	testDirs := make([]string, 0)
-	for _, cloneResult := range cloneResults {
-		if cloneResult.Error != nil {
-			logger.Error(cloneResult.Error.Error())
-			continue
+// runGenerate generates the compatibility report, and writes it to stdout or
+// into the file given by [Options.ReplaceInFile].
+func runGenerate(opts Options, processOpts processOptions, runErrs *runErrors, logger *slog.Logger) error {
+	var err error
+	var nodeMajors []int
+	if opts.NodeMatrix != "" {
+		nodeMajors, err = parseNodeMajors(opts.NodeMatrix)
+		if err != nil {
+			return err
 		}
+	}
 
-		versionedTestsDir := filepath.Join(cloneResult.Directory, cloneResult.TestDirectory)
-		logger.Debug("adding test dir", "dir", versionedTestsDir)
-		testDirs = append(testDirs, versionedTestsDir)
+	var agentFilter *agentVersionFilter
+	if opts.AgentVersion != "" {
+		filter, err := parseAgentVersionFilter(opts.AgentVersion)
+		if err != nil {
+			return err
+		}
+		agentFilter = &filter
 	}
 
+	cloneResults := cloneRepos(configuredRepos(opts), logger)
+
 	logger.Info("processing data")
+	processStart := time.Now()
 	defer func() {
 		cleanupTempDirs(cloneResults, logger)
 	}()
-	data := processVersionedTestDirs(testDirs, logger)
+	prunedData := collectReleaseData(cloneResults, processOpts, runErrs, logger)
+	if len(prunedData) == 0 && runErrs.Len() > 0 {
+		return fmt.Errorf("%w: %d error(s)", ErrTotalFailure, runErrs.Len())
+	}
 
-	aiCompatInputFile := flags.aiCompatJsonFile
+	var laterData []ReleaseData
+	if agentFilter != nil {
+		prunedData, laterData = filterByAgentVersion(prunedData, *agentFilter)
+	}
+
+	aiCompatInputFile := opts.AiCompatJsonFile
 	mainRepoClone := cloneResults[slices.IndexFunc(cloneResults, func(s CloneRepoResult) bool {
 		return s.IsMainRepo == true
 	})]
@@ -118,89 +195,316 @@ func Run(args []string) error {
 	aiCompatDoc := strings.Builder{}
 	err = RenderAiCompatDoc(aiCompatInputFile, &aiCompatDoc)
 	if err != nil {
-		return fmt.Errorf("failed to process ai compat doc: %w", err)
+		runErrs.Add(phaseRender, aiCompatInputFile, err)
+		return fmt.Errorf("%w: failed to process ai compat doc: %w", ErrTotalFailure, err)
 	}
-	logger.Info("data processing complete")
+	logger.Info("data processing complete", logKeyDuration, time.Since(processStart))
 
 	var writeDest io.Writer
-	if flags.replaceInFile != "" {
+	if opts.ReplaceInFile != "" {
 		writeDest = &strings.Builder{}
 	} else {
 		writeDest = os.Stdout
 	}
 
-	slices.SortFunc(data, releaseDataSorter)
-	prunedData := pruneData(data)
-	renderAsMarkdown(prunedData, writeDest)
-	io.WriteString(writeDest, "\n"+aiCompatDoc.String())
+	switch opts.OutputFormat {
+	case outputFormatJson:
+		err = renderAsJson(prunedData, writeDest)
+		if err != nil {
+			runErrs.Add(phaseRender, "", err)
+			return fmt.Errorf("%w: failed to render json: %w", ErrTotalFailure, err)
+		}
+	case outputFormatTerminal:
+		termOpts := terminalOptions{now: time.Now()}
+		if opts.ReplaceInFile == "" {
+			termOpts = detectTerminal(os.Stdout)
+		}
+		if termOpts.isTerminal == false {
+			renderAsTerminal(prunedData, termOpts, writeDest)
+			break
+		}
+		content := &strings.Builder{}
+		renderAsTerminal(prunedData, termOpts, content)
+		err = writePaged(content.String(), writeDest)
+		if err != nil {
+			runErrs.Add(phaseRender, "", err)
+			return fmt.Errorf("%w: failed to page output: %w", ErrTotalFailure, err)
+		}
+	default:
+		renderAsMarkdown(prunedData, writeDest)
+		if agentFilter != nil {
+			io.WriteString(writeDest, "\n\n")
+			renderAgentVersionNote(*agentFilter, laterData, writeDest)
+		}
+		if opts.ShowTestedVersions == true {
+			io.WriteString(writeDest, "\n\n")
+			renderTestedVersions(prunedData, writeDest)
+		}
+		if len(nodeMajors) > 0 {
+			io.WriteString(writeDest, "\n\n")
+			renderNodeMatrix(prunedData, nodeMajors, writeDest)
+		}
+		if hasKnownIncompatible(prunedData) == true {
+			io.WriteString(writeDest, "\n\n")
+			renderKnownIncompatible(prunedData, writeDest)
+		}
+		if opts.ShowDetails == true {
+			io.WriteString(writeDest, "\n\n")
+			renderCoverageDetails(prunedData, writeDest)
+			if hasConflicts(prunedData) == true {
+				io.WriteString(writeDest, "\n\n")
+				renderConflicts(prunedData, writeDest)
+			}
+		}
+		io.WriteString(writeDest, "\n"+aiCompatDoc.String())
+	}
 
-	if flags.replaceInFile != "" {
+	if opts.ReplaceInFile != "" {
 		content := writeDest.(*strings.Builder).String()
-		err = ReplaceInFile(flags.replaceInFile, content, flags.startMarker, flags.endMarker)
+		err = ReplaceInFile(opts.ReplaceInFile, content, opts.StartMarker, opts.EndMarker)
 		if err != nil {
-			return err
+			runErrs.Add(phaseRender, opts.ReplaceInFile, err)
+			return fmt.Errorf("%w: %w", ErrTotalFailure, err)
 		}
 	}
 
 	logger.Info("done")
+	if opts.Strict == true && runErrs.Len() > 0 {
+		return fmt.Errorf("%w: %d error(s)", ErrPartialFailure, runErrs.Len())
+	}
 
 	return nil
 }
 
+// configuredRepos determines the repositories to process from the options.
+func configuredRepos(opts Options) []nrRepo {
+	var repos []nrRepo
+	if opts.RepoDir != "" {
+		testPaths := splitList(opts.TestDir)
+		repoDir := opts.RepoDir
+		if len(testPaths) == 0 {
+			testPaths = []string{"test/versioned"}
+		}
+		var testRepo = nrRepo{repoDir: repoDir, testPaths: testPaths, isMainRepo: true}
+		repos = []nrRepo{testRepo}
+	} else {
+		repos = []nrRepo{agentRepo}
+	}
+
+	if opts.NoExternals == false {
+		repos = append(repos, externalsRepos...)
+	}
+	return repos
+}
+
+// collectReleaseData processes the versioned tests of the cloned
+// repositories into release data. The result is sorted by package name, and
+// duplicate rows are merged. Clone failures are logged and recorded in
+// `runErrs`.
+func collectReleaseData(
+	cloneResults []CloneRepoResult,
+	opts processOptions,
+	runErrs *runErrors,
+	logger *slog.Logger,
+) []ReleaseData {
+	testDirs := make([]versionedTestDir, 0)
+	for _, cloneResult := range cloneResults {
+		if cloneResult.Error != nil {
+			logger.Error(
+				"failed to clone repo",
+				logKeyPhase, phaseClone,
+				logKeyRepo, cloneResult.Repo,
+				logKeyError, cloneResult.Error,
+			)
+			runErrs.Add(phaseClone, "", cloneResult.Error)
+			continue
+		}
+
+		for _, testDirectory := range cloneResult.TestDirectories {
+			versionedTestsDir := filepath.Join(cloneResult.Directory, testDirectory)
+			logger.Debug("adding test dir", logKeyRepo, cloneResult.Repo, "dir", versionedTestsDir)
+			testDirs = append(testDirs, versionedTestDir{
+				repo: cloneResult.Repo,
+				root: cloneResult.Directory,
+				path: versionedTestsDir,
+			})
+		}
+	}
+
+	data := processVersionedTestDirs(testDirs, opts, runErrs, logger)
+	slices.SortFunc(data, releaseDataSorter)
+	mergedData := mergeData(data)
+	for _, info := range mergedData {
+		for _, conflict := range info.Conflicts {
+			logger.Warn(describeConflict(info.Name, conflict), logKeyPackage, info.Name)
+		}
+	}
+	return mergedData
+}
+
+// loadReleaseData reads the release data from the report given by
+// [Options.ReportFile]. Without a report, the configured repositories are
+// cloned and processed, and the clones are removed once the data has been
+// collected.
+func loadReleaseData(
+	opts Options,
+	processOpts processOptions,
+	runErrs *runErrors,
+	logger *slog.Logger,
+) ([]ReleaseData, error) {
+	if opts.ReportFile != "" {
+		return readReleaseDataFile(opts.ReportFile)
+	}
+
+	cloneResults := cloneRepos(configuredRepos(opts), logger)
+	defer cleanupTempDirs(cloneResults, logger)
+
+	logger.Info("processing data")
+	data := collectReleaseData(cloneResults, processOpts, runErrs, logger)
+	if len(data) == 0 && runErrs.Len() > 0 {
+		return nil, fmt.Errorf("%w: %d error(s)", ErrTotalFailure, runErrs.Len())
+	}
+	return data, nil
+}
+
 // cleanupTempDirs removes any temporary directories marked for removal that
 // were created during cloning.
 func cleanupTempDirs(cloneResults []CloneRepoResult, logger *slog.Logger) {
 	for _, cloneResult := range cloneResults {
 		if cloneResult.Remove == false {
-			logger.Debug("not removing directory " + cloneResult.Directory)
+			logger.Debug("not removing directory", logKeyRepo, cloneResult.Repo, "dir", cloneResult.Directory)
 			continue
 		}
-		logger.Debug("removing directory " + cloneResult.Directory)
+		logger.Debug("removing directory", logKeyRepo, cloneResult.Repo, "dir", cloneResult.Directory)
 		_ = appFS.RemoveAll(cloneResult.Directory)
 	}
 }
 
+// processOptions controls how versioned test directories are processed.
+type processOptions struct {
+	discovery discoveryOptions
+
+	// skipMissingFiles excludes test blocks that list test files that do not
+	// exist.
+	skipMissingFiles bool
+
+	// packageName limits the processing to the named module. All modules are
+	// processed when it is empty.
+	packageName string
+
+	// trace records how each source derived its minimum supported version,
+	// see [ReleaseSource.Trace].
+	trace bool
+}
+
 // processVersionedTestDirs iterates through all versioned test directories,
 // looking for versioned `package.json` files, and processes what it finds
-// into release data for each found supported module.
-func processVersionedTestDirs(testDirs []string, logger *slog.Logger) []ReleaseData {
+// into release data for each found supported module. Errors are logged and
+// recorded in `runErrs`, and processing continues with the next module.
+func processVersionedTestDirs(
+	testDirs []versionedTestDir,
+	opts processOptions,
+	runErrs *runErrors,
+	logger *slog.Logger,
+) []ReleaseData {
 	wg := sync.WaitGroup{}
+	lock := sync.Mutex{}
 	results := make([]ReleaseData, 0)
+	packuments := newPackumentCache(NewNpmClient(WithLogger(logger.With(logKeyPhase, phaseRegistry))))
+
+	for _, testDir := range testDirs {
+		parseLogger := logger.With(logKeyPhase, phaseParse, logKeyRepo, testDir.repo)
+		registryLogger := logger.With(logKeyPhase, phaseRegistry, logKeyRepo, testDir.repo)
 
-	for _, versionedTestsDir := range testDirs {
 		iterChan := make(chan dirIterChan)
-		go iterateTestDir(versionedTestsDir, iterChan)
+		go iterateTestDir(testDir.path, opts.discovery, iterChan)
 
-		npm := NewNpmClient(WithLogger(logger))
+		npm := NewNpmClient(WithLogger(registryLogger))
 		for result := range iterChan {
 			if result.err != nil {
-				logger.Error(result.err.Error())
+				parseLogger.Error("failed to read versioned tests", logKeyFile, result.path, logKeyError, result.err)
+				runErrs.Add(phaseParse, result.path, result.err)
 				continue
 			}
 
+			if opts.skipMissingFiles == true {
+				pkgDir := filepath.Dir(result.path)
+				for _, test := range excludeTestsWithMissingFiles(pkgDir, result.pkg) {
+					parseLogger.Warn(
+						"excluding test block with missing test files",
+						logKeyFile, result.path,
+						"missing", strings.Join(missingTestFiles(pkgDir, test), ", "),
+					)
+				}
+			}
+
+			for _, err := range resolveDistTags(result.pkg, packuments) {
+				registryLogger.Warn("could not resolve dist-tag", logKeyFile, result.path, logKeyError, err)
+				runErrs.Add(phaseRegistry, result.path, err)
+			}
+
 			pkgInfos, err := parsePackage(result.pkg)
 			if err != nil {
 				if errors.Is(err, ErrTargetMissing) {
-					logger.Debug(err.Error())
+					parseLogger.Warn("skipping versioned tests", logKeyFile, result.path, logKeyError, err)
 					continue
 				}
 
-				// TODO: this was a hard exit. How should we handle catastrophic errors?
-				logger.Error(err.Error())
+				parseLogger.Error("failed to parse versioned tests", logKeyFile, result.path, logKeyError, err)
+				runErrs.Add(phaseParse, result.path, err)
 				continue
 			}
 
+			sourceFile, err := filepath.Rel(testDir.root, result.path)
+			if err != nil {
+				sourceFile = result.path
+			}
+
 			for _, info := range pkgInfos {
+				if opts.packageName != "" && info.Name != opts.packageName {
+					continue
+				}
+
+				var trace *DerivationTrace
+				if opts.trace == true {
+					trace = info.Trace
+				}
+
+				for _, warning := range info.Warnings {
+					parseLogger.Warn(warning, logKeyPackage, info.Name, logKeyFile, result.path)
+				}
+
 				wg.Add(1)
-				go func(info PkgInfo) {
+				go func(info PkgInfo, trace *DerivationTrace) {
 					defer wg.Done()
-					releaseData, err := buildReleaseData(info, npm)
+					start := time.Now()
+					releaseData, err := buildReleaseData(info, npm, packuments, trace, registryLogger)
 					if err != nil {
-						logger.Error(err.Error())
+						registryLogger.Error(
+							"failed to retrieve release data",
+							logKeyPackage, info.Name,
+							logKeyDuration, time.Since(start),
+							logKeyError, err,
+						)
+						runErrs.Add(phaseRegistry, info.Name, err)
 						return
 					}
+					registryLogger.Debug(
+						"retrieved release data",
+						logKeyPackage, info.Name,
+						logKeyDuration, time.Since(start),
+					)
+					releaseData.Sources = []ReleaseSource{{
+						Repo:                testDir.repo,
+						File:                sourceFile,
+						MinSupportedVersion: releaseData.MinSupportedVersion,
+						MinAgentVersion:     releaseData.MinAgentVersion,
+						Trace:               trace,
+					}}
+					lock.Lock()
 					results = append(results, *releaseData)
-				}(info)
+					lock.Unlock()
+				}(info, trace)
 			}
 		}
 	}
@@ -209,23 +513,16 @@ func processVersionedTestDirs(testDirs []string, logger *slog.Logger) []ReleaseD
 	return results
 }
 
-func buildLogger(verbose bool) *slog.Logger {
-	dest := prettylog.WithDestinationWriter(os.Stderr)
-	level := slog.LevelInfo
-
-	if verbose == true {
-		level = slog.LevelDebug
-	}
-
-	handler := prettylog.New(
-		&slog.HandlerOptions{Level: level},
-		dest,
-	)
-
-	return slog.New(handler)
-}
-
-func buildReleaseData(info PkgInfo, npm *NpmClient) (*ReleaseData, error) {
+// buildReleaseData retrieves the registry data of a target and combines it
+// with the parsed versioned tests. When `trace` is not nil, the values read
+// from the registry responses are recorded in it.
+func buildReleaseData(
+	info PkgInfo,
+	npm *NpmClient,
+	packuments *packumentCache,
+	trace *DerivationTrace,
+	logger *slog.Logger,
+) (*ReleaseData, error) {
 	latest, err := npm.GetLatest(info.Name)
 	if err != nil {
 		return nil, err
@@ -236,76 +533,111 @@ func buildReleaseData(info PkgInfo, npm *NpmClient) (*ReleaseData, error) {
 		return nil, err
 	}
 
-	minReleaseDate := detailedInfo.Time[info.MinVersion]
+	minVersion := resolveMinimumVersion(info, latest, detailedInfo, logger)
+	minReleaseDate := detailedInfo.Time[minVersion]
 	latestReleaseDate := detailedInfo.Time[latest]
 
+	if trace != nil {
+		trace.Responses = append(
+			trace.Responses,
+			RegistryResponse{Url: npm.latestUrl(info.Name), Field: "version", Value: latest},
+		)
+		if info.MinVersionRange != "" && info.MinVersionRange != max_range && minVersion != info.MinVersion {
+			trace.Responses = append(
+				trace.Responses,
+				RegistryResponse{Url: npm.detailedInfoUrl(info.Name), Field: "versions", Value: minVersion},
+			)
+		}
+		trace.Responses = append(
+			trace.Responses,
+			RegistryResponse{
+				Url:   npm.detailedInfoUrl(info.Name),
+				Field: fmt.Sprintf(`time["%s"]`, minVersion),
+				Value: minReleaseDate.ToFullDate().ToString(),
+			},
+			RegistryResponse{
+				Url:   npm.detailedInfoUrl(info.Name),
+				Field: fmt.Sprintf(`time["%s"]`, latest),
+				Value: latestReleaseDate.ToFullDate().ToString(),
+			},
+		)
+	}
+
+	testedVersions, untestedVersions := computeTestedVersions(info, detailedInfo, latest, logger)
+	nodeEngines, minNodeVersion := computeNodeEngines(info)
+
+	for _, problem := range validateRegistryVersions(info, detailedInfo, packuments) {
+		logger.Warn(problem, logKeyPackage, info.Name)
+	}
+
 	result := &ReleaseData{
 		Name:                       info.Name,
-		MinSupportedVersion:        info.MinVersion,
+		MinSupportedVersion:        minVersion,
 		MinSupportedVersionRelease: minReleaseDate.ToFullDate().ToString(),
 		LatestVersion:              latest,
 		LatestVersionRelease:       latestReleaseDate.ToFullDate().ToString(),
 		MinAgentVersion:            info.MinAgentVersion,
+		MinNodeVersion:             minNodeVersion,
+		TestedVersions:             testedVersions,
+		UntestedVersions:           untestedVersions,
+		NodeEngines:                nodeEngines,
+		KnownIncompatible:          info.Incompatible,
+		Tests:                      info.Tests,
+		Warnings:                   info.Warnings,
 	}
 
 	return result, nil
 }
 
-func openPackageJson(path string) (*os.File, error) {
-	pkgJsonFilePath := filepath.Join(path, "package.json")
-	return os.Open(pkgJsonFilePath)
-}
-
-func iterateTestDir(dir string, iterChan chan dirIterChan) {
-	// try to parse package.json from root
-	// in this case it is the only result
-	// so end early
-	rootPkgJson, _ := openPackageJson(dir)
-	if rootPkgJson != nil {
-		pkg, err := readPackageJson(rootPkgJson)
-		if err != nil {
-			iterChan <- dirIterChan{
-				name: dir,
-				err:  fmt.Errorf("failed to read package.json for `%s`: %w", dir, err),
-			}
-		}
-		iterChan <- dirIterChan{name: dir, pkg: pkg}
-		close(iterChan)
-		return
+// resolveMinimumVersion determines the lowest published version of a package
+// that satisfies the range its minimum supported version was derived from.
+// A range of `latest` resolves to the latest published version. When no
+// published version satisfies the range, a warning is logged and the raw
+// lower boundary of the range is returned. A warning is also logged when the
+// resolved version differs from the declared one.
+func resolveMinimumVersion(
+	info PkgInfo,
+	latest string,
+	pkg *NpmDetailedPackage,
+	logger *slog.Logger,
+) string {
+	if info.MinVersionRange == "" {
+		return info.MinVersion
+	}
+	if info.MinVersionRange == max_range {
+		return latest
 	}
 
-	entries, err := os.ReadDir(dir)
+	versionRange, err := semver.NewRange([]byte(info.MinVersionRange))
 	if err != nil {
-		iterChan <- dirIterChan{err: fmt.Errorf("failed to read directory `%s`: %w", dir, err)}
-		close(iterChan)
-		return
+		logger.Warn(
+			"could not parse minimum supported range",
+			logKeyPackage, info.Name,
+			"range", info.MinVersionRange,
+			logKeyError, err,
+		)
+		return info.MinVersion
 	}
 
-	for _, entry := range entries {
-		testDir := filepath.Join(dir, entry.Name())
-		if entry.IsDir() {
-			pkgJsonFile, err := openPackageJson(testDir)
-			if err != nil {
-				iterChan <- dirIterChan{
-					name: entry.Name(),
-					err:  fmt.Errorf("could not find package.json in `%s`: %w", testDir, err),
-				}
-				continue
-			}
-
-			pkg, err := readPackageJson(pkgJsonFile)
-			if err != nil {
-				iterChan <- dirIterChan{
-					name: entry.Name(),
-					err:  fmt.Errorf("failed to read package.json for `%s`: %w", entry.Name(), err),
-				}
-				continue
-			}
-			iterChan <- dirIterChan{name: entry.Name(), pkg: pkg}
-		}
+	version, found := pkg.LowestSatisfying(versionRange)
+	if found == false {
+		logger.Warn(
+			"minimum supported range does not match any published version",
+			logKeyPackage, info.Name,
+			"range", info.MinVersionRange,
+		)
+		return info.MinVersion
 	}
 
-	close(iterChan)
+	if version != info.MinVersion {
+		logger.Warn(
+			"resolved minimum supported version",
+			logKeyPackage, info.Name,
+			"declared", info.MinVersion,
+			"resolved", version,
+		)
+	}
+	return version
 }
 
 // readPackageJson reads a file as a versioned `package.json`.
@@ -327,7 +659,12 @@ func readPackageJson(pkgJsonFile io.Reader) (*VersionedTestPackageJson, error) {
 // cloneRepos clones multiple repositories at once but does not return until
 // all repositories have been cloned.
 func cloneRepos(repos []nrRepo, logger *slog.Logger) []CloneRepoResult {
+	logger = logger.With(logKeyPhase, phaseClone)
+	logger.Info("cloning repositories")
+	start := time.Now()
+
 	wg := sync.WaitGroup{}
+	lock := sync.Mutex{}
 	result := make([]CloneRepoResult, 0)
 	for _, repo := range repos {
 		wg.Add(1)
@@ -335,10 +672,17 @@ func cloneRepos(repos []nrRepo, logger *slog.Logger) []CloneRepoResult {
 			defer wg.Done()
 			cloneResult := cloneRepo(r, logger)
 			cloneResult.IsMainRepo = r.isMainRepo
+			cloneResult.Repo = r.url
+			if r.repoDir != "" {
+				cloneResult.Repo = r.repoDir
+			}
+			lock.Lock()
 			result = append(result, cloneResult)
+			lock.Unlock()
 		}(repo)
 	}
 	wg.Wait()
+	logger.Info("repository cloning complete", logKeyDuration, time.Since(start))
 	return result
 }
 
@@ -348,9 +692,9 @@ func cloneRepos(repos []nrRepo, logger *slog.Logger) []CloneRepoResult {
 func cloneRepo(repo nrRepo, logger *slog.Logger) CloneRepoResult {
 	if repo.repoDir != "" {
 		return CloneRepoResult{
-			Directory:     repo.repoDir,
-			TestDirectory: repo.testPath,
-			Remove:        false,
+			Directory:       repo.repoDir,
+			TestDirectories: repo.testPaths,
+			Remove:          false,
 		}
 	}
 
@@ -361,7 +705,8 @@ func cloneRepo(repo nrRepo, logger *slog.Logger) CloneRepoResult {
 		}
 	}
 
-	logger.Debug("cloning repo", "url", repo.url)
+	logger.Debug("cloning repo", logKeyRepo, repo.url)
+	start := time.Now()
 	_, err = git.PlainClone(repoDir, false, &git.CloneOptions{
 		URL:           repo.url,
 		ReferenceName: plumbing.ReferenceName(repo.branch),
@@ -372,11 +717,12 @@ func cloneRepo(repo nrRepo, logger *slog.Logger) CloneRepoResult {
 			Error: fmt.Errorf("failed to clone repo `%s`: %w", repo.url, err),
 		}
 	}
+	logger.Debug("cloned repo", logKeyRepo, repo.url, logKeyDuration, time.Since(start))
 
 	return CloneRepoResult{
-		Directory:     repoDir,
-		TestDirectory: repo.testPath,
-		Remove:        true,
+		Directory:       repoDir,
+		TestDirectories: repo.testPaths,
+		Remove:          true,
 	}
 }
 
@@ -394,40 +740,6 @@ func releaseDataSorter(a ReleaseData, b ReleaseData) int {
 	}
 }
 
-// pruneData removes duplicate entries from the data set. A duplicate entry
-// is one in which the [ReleaseData.Name] is equal. The entry with the lowest
-// [ReleaseData.MinSupportedVersion] will be kept. The data should be sorted
-// prior to being pruned.
-func pruneData(data []ReleaseData) []ReleaseData {
-	result := make([]ReleaseData, 0)
-	for i := 0; i < len(data); {
-		if i == len(data)-1 {
-			// only one result, just assign to result
-			result = append(result, data[i])
-			break
-		}
-
-		a := data[i]
-		b := data[i+1]
-		if a.Name != b.Name {
-			result = append(result, a)
-			i += 1
-			continue
-		}
-
-		verA, _ := semver.NewVersion([]byte(a.MinSupportedVersion))
-		verB, _ := semver.NewVersion([]byte(b.MinSupportedVersion))
-		if verA.Less(verB) {
-			result = append(result, a)
-		} else {
-			result = append(result, b)
-		}
-		i += 2
-	}
-
-	return result
-}
-
 // renderAsMarkdown renders the collected data as a Markdown table. This is
 // intended to be used when generating output to be embedded in one of docs
 // locations (or maybe to be fed into pandoc to generate a PDF in order to
@@ -444,6 +756,17 @@ func renderAsMarkdown(data []ReleaseData, writer io.Writer) {
 			*When package is not specified, support is within the %snewrelic%s package.
 		`, "`", "`"),
 	)
+
+	if slices.ContainsFunc(data, func(d ReleaseData) bool { return len(d.Warnings) > 0 }) {
+		io.WriteString(
+			writer,
+			heredoc.Doc(`
+
+				⚠️ The compatibility data of the module could not be fully verified
+				against its versioned tests.
+			`),
+		)
+	}
 }
 
 // releaseDataToTable builds the tabular data structure from the discovered
@@ -473,6 +796,8 @@ func releaseDataToTable(data []ReleaseData) table.Writer {
 				value = fmt.Sprintf("`%s`", value)
 			} else if key == "MinAgentVersion" && strings.HasPrefix(value, "@") == true {
 				value = fmt.Sprintf("`%s`", value)
+			} else if key == "MinSupportedVersion" && len(info.Warnings) > 0 {
+				value = fmt.Sprintf("%s ⚠️", value)
 			}
 			row = append(row, value)
 		}
@@ -481,3 +806,113 @@ func releaseDataToTable(data []ReleaseData) table.Writer {
 
 	return outputTable
 }
+
+// renderAsJson renders the collected data as a JSON array. This is intended
+// to be consumed by other tooling.
+func renderAsJson(data []ReleaseData, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// renderTestedVersions renders a Markdown section listing the versions of
+// each module that are exercised by the versioned tests.
+func renderTestedVersions(data []ReleaseData, writer io.Writer) {
+	outputTable := table.NewWriter()
+	outputTable.AppendHeader(table.Row{
+		"Package name",
+		"Tested versions",
+		"Untested published versions",
+	})
+	for _, info := range data {
+		outputTable.AppendRow(table.Row{
+			fmt.Sprintf("`%s`", info.Name),
+			strings.Join(info.TestedVersions, ", "),
+			len(info.UntestedVersions),
+		})
+	}
+
+	io.WriteString(writer, "## Tested versions\n\n")
+	io.WriteString(
+		writer,
+		heredoc.Doc(`
+			The following versions are selected by the versioned test suite. Untested
+			published versions fall within a tested range but are not installed by any
+			test run.
+		`),
+	)
+	io.WriteString(writer, "\n")
+	io.WriteString(writer, outputTable.RenderMarkdown())
+	io.WriteString(writer, "\n")
+}
+
+// hasKnownIncompatible determines if any module in the data set has ranges
+// that are known to be incompatible.
+func hasKnownIncompatible(data []ReleaseData) bool {
+	return slices.ContainsFunc(data, func(d ReleaseData) bool {
+		return len(d.KnownIncompatible) > 0
+	})
+}
+
+// renderKnownIncompatible renders a Markdown section listing the ranges of
+// each module that are explicitly not supported, along with the reason.
+func renderKnownIncompatible(data []ReleaseData, writer io.Writer) {
+	outputTable := table.NewWriter()
+	outputTable.AppendHeader(table.Row{"Package name", "Versions", "Reason"})
+	for _, info := range data {
+		for _, incompatible := range info.KnownIncompatible {
+			outputTable.AppendRow(table.Row{
+				fmt.Sprintf("`%s`", info.Name),
+				incompatible.Versions,
+				incompatible.Comment,
+			})
+		}
+	}
+
+	io.WriteString(writer, "## Known incompatible versions\n\n")
+	io.WriteString(
+		writer,
+		heredoc.Doc(`
+			The following versions are known to be incompatible with the agent and
+			are not instrumented.
+		`),
+	)
+	io.WriteString(writer, "\n")
+	io.WriteString(writer, outputTable.RenderMarkdown())
+	io.WriteString(writer, "\n")
+}
+
+// renderCoverageDetails renders a Markdown section that details, for each
+// module, the versioned test blocks that cover it. Each module's details are
+// placed within an expandable block.
+func renderCoverageDetails(data []ReleaseData, writer io.Writer) {
+	io.WriteString(writer, "## Coverage details\n")
+
+	for _, info := range data {
+		outputTable := table.NewWriter()
+		outputTable.AppendHeader(table.Row{"Version range", "Samples", "Node.js", "Test files"})
+		for _, test := range info.Tests {
+			samples := "all"
+			if test.Samples > 0 {
+				samples = fmt.Sprintf("%d", test.Samples)
+			}
+
+			files := make([]string, 0, len(test.Files))
+			for _, file := range test.Files {
+				files = append(files, fmt.Sprintf("`%s`", file))
+			}
+
+			outputTable.AppendRow(table.Row{
+				test.Versions,
+				samples,
+				test.Node,
+				strings.Join(files, ", "),
+			})
+		}
+
+		io.WriteString(writer, "\n<details>\n")
+		io.WriteString(writer, fmt.Sprintf("<summary><code>%s</code></summary>\n\n", info.Name))
+		io.WriteString(writer, outputTable.RenderMarkdown())
+		io.WriteString(writer, "\n\n</details>\n")
+	}
+}
@@ -29,7 +29,9 @@ func Test_RenderCompatDoc(t *testing.T) {
 		"--ai-compat-json", "testdata/ai-compat.json",
 		"--replace-in-file", outFilePath,
 	}
-	err = main.Run(args)
+	opts, err := main.ParseOptions(args)
+	require.Nil(t, err)
+	err = main.Run(opts)
 	require.Nil(t, err)
 
 	fileData, err := os.ReadFile(outFilePath)
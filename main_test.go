@@ -1,7 +1,6 @@
 package main
 
 import (
-	"context"
 	"errors"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -55,9 +54,11 @@ func Test_cleanupTempDirs(t *testing.T) {
 	}
 	cleanupTempDirs(cloneResults, logger)
 	assert.Equal(t, 3, len(collector.logs))
-	assert.Contains(t, collector.logs[0], "not removing directory /foo")
-	assert.Contains(t, collector.logs[1], "removing directory /bar")
-	assert.Contains(t, collector.logs[2], "removing directory /baz")
+	assert.Contains(t, collector.logs[0], `msg="not removing directory"`)
+	assert.Contains(t, collector.logs[0], "dir=/foo")
+	assert.Contains(t, collector.logs[1], `msg="removing directory"`)
+	assert.Contains(t, collector.logs[1], "dir=/bar")
+	assert.Contains(t, collector.logs[2], "dir=/baz")
 
 	exists, _ := afero.Exists(appFS, "/foo")
 	assert.Equal(t, true, exists)
@@ -67,31 +68,126 @@ func Test_cleanupTempDirs(t *testing.T) {
 	assert.Equal(t, false, exists)
 }
 
-func Test_buildLogger(t *testing.T) {
-	t.Run("returns debug level logger", func(t *testing.T) {
-		logger := buildLogger(true)
-		assert.Equal(t, true, logger.Handler().Enabled(context.TODO(), slog.LevelError))
-		assert.Equal(t, true, logger.Handler().Enabled(context.TODO(), slog.LevelDebug))
-	})
-
-	t.Run("returns standard logger", func(t *testing.T) {
-		logger := buildLogger(false)
-		assert.Equal(t, true, logger.Handler().Enabled(context.TODO(), slog.LevelInfo))
-		assert.Equal(t, true, logger.Handler().Enabled(context.TODO(), slog.LevelError))
-		assert.Equal(t, false, logger.Handler().Enabled(context.TODO(), slog.LevelDebug))
-	})
-}
-
 func Test_processVersionedTestDirs(t *testing.T) {
 	t.Run("parses a versioned test dir", func(t *testing.T) {
 		collector := &logCollector{}
 		logger := slog.New(slog.NewJSONHandler(collector, &slog.HandlerOptions{Level: slog.LevelError}))
-		testDirs := []string{"testdata/versioned"}
+		testDirs := []versionedTestDir{{repo: ".", root: ".", path: "testdata/versioned"}}
+
+		runErrs := &runErrors{}
 
-		releaseData := processVersionedTestDirs(testDirs, logger)
+		releaseData := processVersionedTestDirs(testDirs, processOptions{}, runErrs, logger)
 		assert.Equal(t, 0, len(collector.logs))
+		assert.Equal(t, 0, runErrs.Len())
 		assert.Equal(t, 14, len(releaseData))
 	})
+
+	t.Run("skips test blocks with missing files", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		runErrs := &runErrors{}
+
+		releaseData := processVersionedTestDirs(
+			[]versionedTestDir{{path: "testdata/lint/missing-files"}},
+			processOptions{skipMissingFiles: true},
+			runErrs,
+			logger,
+		)
+		assert.Empty(t, releaseData)
+		assert.Equal(t, 0, runErrs.Len())
+	})
+
+	t.Run("records parse errors", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		runErrs := &runErrors{}
+
+		releaseData := processVersionedTestDirs(
+			[]versionedTestDir{{path: "testdata/lint/broken"}},
+			processOptions{},
+			runErrs,
+			logger,
+		)
+		assert.Empty(t, releaseData)
+		assert.Equal(t, 1, runErrs.Len())
+		assert.Equal(t, phaseParse, runErrs.errors[0].Phase)
+	})
+}
+
+func Test_buildReleaseData(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/foo/latest":
+			io.WriteString(res, `{"name":"foo","version":"2.1.0"}`)
+		case "/foo":
+			io.WriteString(res, `{
+				"versions": {"1.0.0": {}, "1.2.0": {}, "2.1.0": {}},
+				"time": {
+					"1.0.0": "2020-01-02T00:00:00.000Z",
+					"1.2.0": "2021-03-04T00:00:00.000Z",
+					"2.1.0": "2024-05-06T00:00:00.000Z"
+				}
+			}`)
+		default:
+			res.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+	npm := NewNpmClient(WithBaseUrl(ts.URL))
+	info := PkgInfo{Name: "foo", MinVersion: "1.1.0", MinVersionRange: ">=1.1.0"}
+
+	trace := &DerivationTrace{}
+	releaseData, err := buildReleaseData(info, npm, newPackumentCache(npm), trace, nilLogger)
+	require.Nil(t, err)
+	assert.Equal(t, "1.2.0", releaseData.MinSupportedVersion)
+	assert.Equal(t, "2021-03-04", releaseData.MinSupportedVersionRelease)
+	assert.Equal(t, []RegistryResponse{
+		{Url: ts.URL + "/foo/latest", Field: "version", Value: "2.1.0"},
+		{Url: ts.URL + "/foo", Field: "versions", Value: "1.2.0"},
+		{Url: ts.URL + "/foo", Field: `time["1.2.0"]`, Value: "2021-03-04"},
+		{Url: ts.URL + "/foo", Field: `time["2.1.0"]`, Value: "2024-05-06"},
+	}, trace.Responses)
+}
+
+func Test_resolveMinimumVersion(t *testing.T) {
+	pkg := &NpmDetailedPackage{
+		Versions: map[string]any{
+			"2.0.1": nil,
+			"2.5.0": nil,
+			"3.0.0": nil,
+		},
+	}
+
+	t.Run("resolves to lowest published version", func(t *testing.T) {
+		collector := &logCollector{}
+		logger := slog.New(slog.NewTextHandler(collector, nil))
+		info := PkgInfo{Name: "foo", MinVersion: "2.0.0", MinVersionRange: ">=2.0.0"}
+		found := resolveMinimumVersion(info, "3.0.0", pkg, logger)
+		assert.Equal(t, "2.0.1", found)
+		require.Equal(t, 1, len(collector.logs))
+		assert.Contains(t, collector.logs[0], `level=WARN msg="resolved minimum supported version"`)
+		assert.Contains(t, collector.logs[0], "package=foo declared=2.0.0 resolved=2.0.1")
+	})
+
+	t.Run("resolves latest to latest version", func(t *testing.T) {
+		info := PkgInfo{Name: "foo", MinVersion: "0.0.0", MinVersionRange: max_range}
+		found := resolveMinimumVersion(info, "3.0.0", pkg, nilLogger)
+		assert.Equal(t, "3.0.0", found)
+	})
+
+	t.Run("keeps version when range is unknown", func(t *testing.T) {
+		info := PkgInfo{Name: "foo", MinVersion: "2.0.0"}
+		found := resolveMinimumVersion(info, "3.0.0", pkg, nilLogger)
+		assert.Equal(t, "2.0.0", found)
+	})
+
+	t.Run("warns when no published version matches", func(t *testing.T) {
+		collector := &logCollector{}
+		logger := slog.New(slog.NewTextHandler(collector, nil))
+		info := PkgInfo{Name: "foo", MinVersion: "4.0.0", MinVersionRange: ">=4.0.0"}
+		found := resolveMinimumVersion(info, "3.0.0", pkg, logger)
+		assert.Equal(t, "4.0.0", found)
+		require.Equal(t, 1, len(collector.logs))
+		assert.Contains(t, collector.logs[0], "does not match any published version")
+	})
 }
 
 func Test_readPackageJson(t *testing.T) {
@@ -150,14 +246,14 @@ func Test_cloneRepos(t *testing.T) {
 	t.Run("clones multiple repos", func(t *testing.T) {
 		appFS = afero.NewMemMapFs()
 		repos := []nrRepo{
-			{url: "testdata/bare-repo.git", testPath: "a"},
-			{url: "testdata/bare-repo.git", testPath: "b"},
+			{url: "testdata/bare-repo.git", testPaths: []string{"a"}},
+			{url: "testdata/bare-repo.git", testPaths: []string{"b"}},
 		}
 		results := cloneRepos(repos, nilLogger)
 		assert.Equal(t, 2, len(results))
 		for _, result := range results {
 			assert.Nil(t, result.Error)
-			assert.Equal(t, true, strings.ContainsAny(result.TestDirectory, "ab"))
+			assert.Equal(t, true, strings.ContainsAny(result.TestDirectories[0], "ab"))
 			assert.Equal(t, true, strings.Contains(result.Directory, "/newrelic"))
 		}
 	})
@@ -171,21 +267,21 @@ func Test_cloneRepo(t *testing.T) {
 
 	t.Run("returns repo info if local repo dir provided", func(t *testing.T) {
 		repo := nrRepo{
-			repoDir:  "/foo/bar",
-			testPath: "versioned/tests",
+			repoDir:   "/foo/bar",
+			testPaths: []string{"versioned/tests"},
 		}
 		result := cloneRepo(repo, nilLogger)
 		assert.Nil(t, result.Error)
 		assert.Equal(t, result.Directory, "/foo/bar")
-		assert.Equal(t, result.TestDirectory, "versioned/tests")
+		assert.Equal(t, result.TestDirectories, []string{"versioned/tests"})
 	})
 
 	t.Run("returns error from creating temp dir", func(t *testing.T) {
 		appFS = afero.NewReadOnlyFs(afero.NewMemMapFs())
 		repo := nrRepo{
-			url:      "https://git.example.com/foo",
-			branch:   "main",
-			testPath: "test/versioned",
+			url:       "https://git.example.com/foo",
+			branch:    "main",
+			testPaths: []string{"test/versioned"},
 		}
 		result := cloneRepo(repo, nilLogger)
 		assert.NotNil(t, result.Error)
@@ -201,9 +297,9 @@ func Test_cloneRepo(t *testing.T) {
 		defer ts.Close()
 
 		repo := nrRepo{
-			url:      ts.URL,
-			branch:   "main",
-			testPath: "test/versioned",
+			url:       ts.URL,
+			branch:    "main",
+			testPaths: []string{"test/versioned"},
 		}
 		result := cloneRepo(repo, nilLogger)
 		assert.NotNil(t, result.Error)
@@ -213,14 +309,14 @@ func Test_cloneRepo(t *testing.T) {
 	t.Run("clones repo into temp dir", func(t *testing.T) {
 		appFS = afero.NewMemMapFs()
 		repo := nrRepo{
-			url:      "testdata/bare-repo.git",
-			branch:   "main",
-			testPath: "test/versioned",
+			url:       "testdata/bare-repo.git",
+			branch:    "main",
+			testPaths: []string{"test/versioned"},
 		}
 		result := cloneRepo(repo, nilLogger)
 		assert.Nil(t, result.Error)
 		assert.Equal(t, true, strings.Contains(result.Directory, "/newrelic"))
-		assert.Equal(t, "test/versioned", result.TestDirectory)
+		assert.Equal(t, []string{"test/versioned"}, result.TestDirectories)
 	})
 }
 
@@ -238,61 +334,6 @@ func Test_releaseDataSorter(t *testing.T) {
 	assert.Equal(t, -1, releaseDataSorter(a, b))
 }
 
-func Test_pruneData(t *testing.T) {
-	// Short circuits for a single element.
-	input := []ReleaseData{
-		{Name: "foo", MinSupportedVersion: "1.0.0"},
-	}
-	expected := []ReleaseData{
-		{Name: "foo", MinSupportedVersion: "1.0.0"},
-	}
-	assert.Equal(t, expected, pruneData(input))
-
-	// Drops a literal duplicate.
-	input = []ReleaseData{
-		{Name: "foo", MinSupportedVersion: "1.0.0"},
-		{Name: "foo", MinSupportedVersion: "1.0.0"},
-	}
-	expected = []ReleaseData{
-		{Name: "foo", MinSupportedVersion: "1.0.0"},
-	}
-	assert.Equal(t, expected, pruneData(input))
-
-	// Picks first one.
-	input = []ReleaseData{
-		{Name: "foo", MinSupportedVersion: "1.0.0"},
-		{Name: "foo", MinSupportedVersion: "2.0.0"},
-	}
-	expected = []ReleaseData{
-		{Name: "foo", MinSupportedVersion: "1.0.0"},
-	}
-	assert.Equal(t, expected, pruneData(input))
-
-	// Picks second one.
-	input = []ReleaseData{
-		{Name: "foo", MinSupportedVersion: "2.0.0"},
-		{Name: "foo", MinSupportedVersion: "1.0.0"},
-	}
-	expected = []ReleaseData{
-		{Name: "foo", MinSupportedVersion: "1.0.0"},
-	}
-	assert.Equal(t, expected, pruneData(input))
-
-	// All-in-one.
-	input = []ReleaseData{
-		{Name: "foo", MinSupportedVersion: "2.0.0"},
-		{Name: "foo", MinSupportedVersion: "1.0.0"},
-		{Name: "bar", MinSupportedVersion: "1.0.0"},
-		{Name: "baz", MinSupportedVersion: "3.0.0"},
-	}
-	expected = []ReleaseData{
-		{Name: "foo", MinSupportedVersion: "1.0.0"},
-		{Name: "bar", MinSupportedVersion: "1.0.0"},
-		{Name: "baz", MinSupportedVersion: "3.0.0"},
-	}
-	assert.Equal(t, expected, pruneData(input))
-}
-
 func Test_releaseDataToTable(t *testing.T) {
 	input := []ReleaseData{
 		{
@@ -302,6 +343,7 @@ func Test_releaseDataToTable(t *testing.T) {
 			LatestVersion:              "2.0.0",
 			LatestVersionRelease:       "2024-05-21",
 			MinAgentVersion:            "2.0.0",
+			MinNodeVersion:             "18.0.0",
 		},
 		{
 			Name:                       "@foo/bar",
@@ -317,4 +359,124 @@ func Test_releaseDataToTable(t *testing.T) {
 
 	found := releaseDataToTable(input)
 	assert.Equal(t, expected, []byte(found.RenderMarkdown()+"\n"))
+
+	t.Run("annotates rows with warnings", func(t *testing.T) {
+		input := []ReleaseData{
+			{Name: "foo", MinSupportedVersion: "1.0.0", Warnings: []string{"bad"}},
+		}
+		found := releaseDataToTable(input)
+		assert.Contains(t, found.RenderMarkdown(), "| `foo` | 1.0.0 ⚠️ |")
+	})
+}
+
+func Test_renderAsJson(t *testing.T) {
+	input := []ReleaseData{
+		{
+			Name:                "foo",
+			MinSupportedVersion: "1.0.0",
+			TestedVersions:      []string{"1.0.0", "1.1.0"},
+			UntestedVersions:    []string{"1.0.1"},
+		},
+	}
+
+	builder := strings.Builder{}
+	err := renderAsJson(input, &builder)
+	require.Nil(t, err)
+	assert.Contains(t, builder.String(), `"name": "foo"`)
+	assert.Contains(t, builder.String(), `"testedVersions": [`)
+	assert.Contains(t, builder.String(), `"untestedVersions": [`)
+
+	t.Run("keeps the key order of the report", func(t *testing.T) {
+		builder := strings.Builder{}
+		err := renderAsJson([]ReleaseData{{Name: "foo"}}, &builder)
+		require.Nil(t, err)
+
+		keys := []string{
+			`"name"`,
+			`"minSupportedVersion"`,
+			`"minSupportedVersionRelease"`,
+			`"latestVersion"`,
+			`"latestVersionRelease"`,
+			`"minAgentVersion"`,
+			`"minNodeVersion"`,
+			`"testedVersions"`,
+			`"untestedVersions"`,
+			`"nodeEngines"`,
+			`"tests"`,
+		}
+		found := builder.String()
+		for i := 1; i < len(keys); i += 1 {
+			assert.Less(t, strings.Index(found, keys[i-1]), strings.Index(found, keys[i]), "%s before %s", keys[i-1], keys[i])
+		}
+	})
+}
+
+func Test_renderTestedVersions(t *testing.T) {
+	input := []ReleaseData{
+		{
+			Name:             "foo",
+			TestedVersions:   []string{"1.0.0", "1.1.0"},
+			UntestedVersions: []string{"1.0.1", "1.0.2"},
+		},
+	}
+
+	builder := strings.Builder{}
+	renderTestedVersions(input, &builder)
+	assert.Contains(t, builder.String(), "## Tested versions")
+	assert.Contains(t, builder.String(), "| `foo` | 1.0.0, 1.1.0 | 2 |")
+}
+
+func Test_renderKnownIncompatible(t *testing.T) {
+	input := []ReleaseData{
+		{Name: "bar"},
+		{
+			Name: "foo",
+			KnownIncompatible: []IncompatibleRange{
+				{Versions: "1.2.0", Comment: "Breaks the router."},
+			},
+		},
+	}
+	assert.Equal(t, true, hasKnownIncompatible(input))
+	assert.Equal(t, false, hasKnownIncompatible(input[0:1]))
+
+	builder := strings.Builder{}
+	renderKnownIncompatible(input, &builder)
+	assert.Contains(t, builder.String(), "## Known incompatible versions")
+	assert.Contains(t, builder.String(), "| `foo` | 1.2.0 | Breaks the router. |")
+	assert.NotContains(t, builder.String(), "`bar`")
+}
+
+func Test_renderCoverageDetails(t *testing.T) {
+	input := []ReleaseData{
+		{
+			Name: "foo",
+			Tests: []TargetTest{
+				{Versions: ">=2.0.0", Samples: 5, Node: ">=18", Files: []string{"a.tap.js", "b.tap.js"}},
+				{Versions: "<2.0.0", Files: []string{"legacy.tap.js"}},
+			},
+		},
+	}
+
+	builder := strings.Builder{}
+	renderCoverageDetails(input, &builder)
+	found := builder.String()
+	assert.Contains(t, found, "## Coverage details")
+	assert.Contains(t, found, "<summary><code>foo</code></summary>")
+	assert.Contains(t, found, "| >=2.0.0 | 5 | >=18 | `a.tap.js`, `b.tap.js` |")
+	assert.Contains(t, found, "| <2.0.0 | all |  | `legacy.tap.js` |")
+	assert.Contains(t, found, "</details>")
+}
+
+func Test_renderAsMarkdown(t *testing.T) {
+	t.Run("omits warning footnote", func(t *testing.T) {
+		builder := strings.Builder{}
+		renderAsMarkdown([]ReleaseData{{Name: "foo"}}, &builder)
+		assert.NotContains(t, builder.String(), "⚠️")
+	})
+
+	t.Run("includes warning footnote", func(t *testing.T) {
+		builder := strings.Builder{}
+		renderAsMarkdown([]ReleaseData{{Name: "foo", Warnings: []string{"bad"}}}, &builder)
+		assert.Contains(t, builder.String(), "⚠️ The compatibility data of the module")
+	})
 }
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"blitznote.com/src/semver/v3"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+const conflictFieldMinSupported = "minSupportedVersion"
+const conflictFieldMinAgent = "minAgentVersion"
+
+// mergeData combines entries in the data set that describe the same package,
+// i.e. entries in which the [ReleaseData.Name] is equal. Any number of
+// entries may describe the same package, and they need not be adjacent. The
+// entry with the lowest [ReleaseData.MinSupportedVersion] supplies the
+// versions of the merged row, while the tests, sources, and warnings of every
+// entry are combined. Disagreements between the entries on the minimum
+// supported version or the minimum agent version are recorded in
+// [ReleaseData.Conflicts]. The order of first appearance is retained.
+func mergeData(data []ReleaseData) []ReleaseData {
+	names := make([]string, 0)
+	groups := make(map[string][]ReleaseData)
+	for _, entry := range data {
+		if _, found := groups[entry.Name]; found == false {
+			names = append(names, entry.Name)
+		}
+		groups[entry.Name] = append(groups[entry.Name], entry)
+	}
+
+	result := make([]ReleaseData, 0, len(names))
+	for _, name := range names {
+		result = append(result, mergeReleaseData(groups[name]))
+	}
+	return result
+}
+
+// mergeReleaseData merges a group of entries that describe the same package
+// into a single entry.
+func mergeReleaseData(group []ReleaseData) ReleaseData {
+	if len(group) == 1 {
+		return group[0]
+	}
+
+	winner := 0
+	for i := 1; i < len(group); i += 1 {
+		if isVersionLower(group[i].MinSupportedVersion, group[winner].MinSupportedVersion) {
+			winner = i
+		}
+	}
+
+	merged := group[winner]
+	merged.Tests = nil
+	merged.NodeEngines = nil
+	merged.KnownIncompatible = nil
+	merged.Warnings = nil
+	merged.Sources = nil
+	merged.Conflicts = nil
+
+	tested := make([]string, 0)
+	untested := make([]string, 0)
+	for _, entry := range group {
+		if isVersionLower(entry.MinNodeVersion, merged.MinNodeVersion) || merged.MinNodeVersion == "" {
+			merged.MinNodeVersion = entry.MinNodeVersion
+		}
+
+		merged.Tests = append(merged.Tests, entry.Tests...)
+		merged.Sources = append(merged.Sources, entry.Sources...)
+		merged.NodeEngines = appendUnique(merged.NodeEngines, entry.NodeEngines...)
+		merged.KnownIncompatible = appendUnique(merged.KnownIncompatible, entry.KnownIncompatible...)
+		merged.Warnings = appendUnique(merged.Warnings, entry.Warnings...)
+		tested = appendUnique(tested, entry.TestedVersions...)
+		untested = appendUnique(untested, entry.UntestedVersions...)
+	}
+
+	untested = slices.DeleteFunc(untested, func(v string) bool { return slices.Contains(tested, v) })
+	merged.TestedVersions = sortVersionStrings(tested)
+	merged.UntestedVersions = sortVersionStrings(untested)
+
+	merged.Conflicts = appendConflict(merged.Conflicts, conflictFieldMinSupported, group, func(entry ReleaseData) string {
+		return entry.MinSupportedVersion
+	})
+	merged.Conflicts = appendConflict(merged.Conflicts, conflictFieldMinAgent, group, func(entry ReleaseData) string {
+		return entry.MinAgentVersion
+	})
+
+	return merged
+}
+
+// appendConflict appends a conflict for the field to the set of conflicts if
+// the entries of the group supply more than one value for the field.
+func appendConflict(
+	conflicts []MergeConflict,
+	field string,
+	group []ReleaseData,
+	value func(ReleaseData) string,
+) []MergeConflict {
+	values := make([]ConflictValue, 0)
+	for _, entry := range group {
+		idx := slices.IndexFunc(values, func(cv ConflictValue) bool { return cv.Value == value(entry) })
+		if idx == -1 {
+			values = append(values, ConflictValue{Value: value(entry)})
+			idx = len(values) - 1
+		}
+		values[idx].Sources = append(values[idx].Sources, entry.Sources...)
+	}
+
+	if len(values) < 2 {
+		return conflicts
+	}
+	return append(conflicts, MergeConflict{Field: field, Values: values})
+}
+
+// appendUnique appends the items to the slice, skipping items that are
+// already present.
+func appendUnique[T comparable](slice []T, items ...T) []T {
+	for _, item := range items {
+		if slices.Contains(slice, item) == false {
+			slice = append(slice, item)
+		}
+	}
+	return slice
+}
+
+// isVersionLower determines if version `a` is lower than version `b`. A
+// version that cannot be parsed is never lower than another version.
+func isVersionLower(a string, b string) bool {
+	verA, err := semver.NewVersion([]byte(a))
+	if err != nil {
+		return false
+	}
+	verB, err := semver.NewVersion([]byte(b))
+	if err != nil {
+		return true
+	}
+	return verA.Less(verB)
+}
+
+// sortVersionStrings sorts a set of version strings in ascending order.
+func sortVersionStrings(versions []string) []string {
+	slices.SortStableFunc(versions, func(a string, b string) int {
+		switch {
+		case isVersionLower(a, b):
+			return -1
+		case isVersionLower(b, a):
+			return 1
+		default:
+			return 0
+		}
+	})
+	return versions
+}
+
+// hasConflicts determines if any of the rows have conflicting sources.
+func hasConflicts(data []ReleaseData) bool {
+	return slices.ContainsFunc(data, func(info ReleaseData) bool {
+		return len(info.Conflicts) > 0
+	})
+}
+
+// describeConflict renders a single line description of a conflict.
+func describeConflict(name string, conflict MergeConflict) string {
+	values := make([]string, 0, len(conflict.Values))
+	for _, value := range conflict.Values {
+		values = append(values, fmt.Sprintf("`%s` (%s)", value.Value, describeSources(value.Sources)))
+	}
+	return fmt.Sprintf("`%s` has conflicting %s: %s", name, conflict.Field, strings.Join(values, ", "))
+}
+
+// describeSources renders a set of sources as a comma separated list.
+func describeSources(sources []ReleaseSource) string {
+	results := make([]string, 0, len(sources))
+	for _, source := range sources {
+		results = append(results, source.String())
+	}
+	return strings.Join(results, ", ")
+}
+
+// renderConflicts renders a Markdown section that lists, for every package
+// with conflicting sources, the values supplied by each source.
+func renderConflicts(data []ReleaseData, writer io.Writer) {
+	outputTable := table.NewWriter()
+	outputTable.AppendHeader(table.Row{"Package name", "Field", "Value", "Sources"})
+
+	for _, info := range data {
+		for _, conflict := range info.Conflicts {
+			for _, value := range conflict.Values {
+				sources := make([]string, 0, len(value.Sources))
+				for _, source := range value.Sources {
+					sources = append(sources, fmt.Sprintf("`%s`", source))
+				}
+				outputTable.AppendRow(table.Row{
+					fmt.Sprintf("`%s`", info.Name),
+					conflict.Field,
+					value.Value,
+					strings.Join(sources, ", "),
+				})
+			}
+		}
+	}
+
+	io.WriteString(writer, "## Conflicting sources\n\n")
+	io.WriteString(writer, outputTable.RenderMarkdown())
+	io.WriteString(writer, "\n")
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_mergeData(t *testing.T) {
+	t.Run("keeps a single element", func(t *testing.T) {
+		input := []ReleaseData{
+			{Name: "foo", MinSupportedVersion: "1.0.0"},
+		}
+		assert.Equal(t, input, mergeData(input))
+	})
+
+	t.Run("keeps the lowest minimum supported version", func(t *testing.T) {
+		input := []ReleaseData{
+			{Name: "foo", MinSupportedVersion: "2.0.0"},
+			{Name: "foo", MinSupportedVersion: "1.0.0"},
+			{Name: "bar", MinSupportedVersion: "1.0.0"},
+			{Name: "baz", MinSupportedVersion: "3.0.0"},
+		}
+		found := mergeData(input)
+		assert.Equal(t, 3, len(found))
+		assert.Equal(t, "foo", found[0].Name)
+		assert.Equal(t, "1.0.0", found[0].MinSupportedVersion)
+		assert.Equal(t, "bar", found[1].Name)
+		assert.Equal(t, "baz", found[2].Name)
+	})
+
+	t.Run("merges any number of duplicates", func(t *testing.T) {
+		a := ReleaseSource{Repo: "agent", File: "test/versioned/foo/package.json"}
+		b := ReleaseSource{Repo: "agent", File: "test/versioned/foo-esm/package.json"}
+		c := ReleaseSource{Repo: "plugin", File: "tests/versioned/package.json"}
+		input := []ReleaseData{
+			{
+				Name:                "foo",
+				MinSupportedVersion: "2.0.0",
+				MinAgentVersion:     "1.0.0",
+				MinNodeVersion:      "18.0.0",
+				TestedVersions:      []string{"2.0.0", "3.0.0"},
+				UntestedVersions:    []string{"2.5.0"},
+				Tests:               []TargetTest{{Versions: ">=2"}},
+				Warnings:            []string{"bad"},
+				Sources:             []ReleaseSource{a},
+			},
+			{
+				Name:                "foo",
+				MinSupportedVersion: "1.0.0",
+				MinAgentVersion:     "1.0.0",
+				MinNodeVersion:      "20.0.0",
+				TestedVersions:      []string{"1.0.0", "3.0.0"},
+				UntestedVersions:    []string{"2.0.0", "1.5.0"},
+				Tests:               []TargetTest{{Versions: ">=1"}},
+				Warnings:            []string{"bad"},
+				Sources:             []ReleaseSource{b},
+			},
+			{
+				Name:                "foo",
+				MinSupportedVersion: "2.0.0",
+				MinAgentVersion:     "@newrelic/foo@1.0.0",
+				Tests:               []TargetTest{{Versions: ">=2"}},
+				Sources:             []ReleaseSource{c},
+			},
+		}
+
+		found := mergeData(input)
+		assert.Equal(t, 1, len(found))
+
+		merged := found[0]
+		assert.Equal(t, "1.0.0", merged.MinSupportedVersion)
+		assert.Equal(t, "1.0.0", merged.MinAgentVersion)
+		assert.Equal(t, "18.0.0", merged.MinNodeVersion)
+		assert.Equal(t, []string{"1.0.0", "2.0.0", "3.0.0"}, merged.TestedVersions)
+		assert.Equal(t, []string{"1.5.0", "2.5.0"}, merged.UntestedVersions)
+		assert.Equal(t, []TargetTest{{Versions: ">=2"}, {Versions: ">=1"}, {Versions: ">=2"}}, merged.Tests)
+		assert.Equal(t, []string{"bad"}, merged.Warnings)
+		assert.Equal(t, []ReleaseSource{a, b, c}, merged.Sources)
+
+		expectedConflicts := []MergeConflict{
+			{
+				Field: "minSupportedVersion",
+				Values: []ConflictValue{
+					{Value: "2.0.0", Sources: []ReleaseSource{a, c}},
+					{Value: "1.0.0", Sources: []ReleaseSource{b}},
+				},
+			},
+			{
+				Field: "minAgentVersion",
+				Values: []ConflictValue{
+					{Value: "1.0.0", Sources: []ReleaseSource{a, b}},
+					{Value: "@newrelic/foo@1.0.0", Sources: []ReleaseSource{c}},
+				},
+			},
+		}
+		assert.Equal(t, expectedConflicts, merged.Conflicts)
+	})
+
+	t.Run("does not report agreeing duplicates", func(t *testing.T) {
+		input := []ReleaseData{
+			{Name: "foo", MinSupportedVersion: "1.0.0", MinAgentVersion: "2.0.0"},
+			{Name: "foo", MinSupportedVersion: "1.0.0", MinAgentVersion: "2.0.0"},
+		}
+		found := mergeData(input)
+		assert.Equal(t, 1, len(found))
+		assert.Empty(t, found[0].Conflicts)
+	})
+}
+
+func Test_describeConflict(t *testing.T) {
+	conflict := MergeConflict{
+		Field: "minAgentVersion",
+		Values: []ConflictValue{
+			{Value: "1.0.0", Sources: []ReleaseSource{{Repo: "agent", File: "a/package.json"}}},
+			{Value: "2.0.0", Sources: []ReleaseSource{{File: "b/package.json"}}},
+		},
+	}
+	expected := "`foo` has conflicting minAgentVersion: `1.0.0` (agent:a/package.json), `2.0.0` (b/package.json)"
+	assert.Equal(t, expected, describeConflict("foo", conflict))
+}
+
+func Test_renderConflicts(t *testing.T) {
+	input := []ReleaseData{
+		{Name: "bar"},
+		{
+			Name: "foo",
+			Conflicts: []MergeConflict{{
+				Field: "minSupportedVersion",
+				Values: []ConflictValue{
+					{Value: "1.0.0", Sources: []ReleaseSource{{Repo: "agent", File: "a/package.json"}}},
+					{Value: "2.0.0", Sources: []ReleaseSource{{Repo: "agent", File: "b/package.json"}}},
+				},
+			}},
+		},
+	}
+	expected := strings.Join([]string{
+		"## Conflicting sources",
+		"",
+		"| Package name | Field | Value | Sources |",
+		"| --- | --- | --- | --- |",
+		"| `foo` | minSupportedVersion | 1.0.0 | `agent:a/package.json` |",
+		"| `foo` | minSupportedVersion | 2.0.0 | `agent:b/package.json` |",
+		"",
+	}, "\n")
+
+	builder := strings.Builder{}
+	renderConflicts(input, &builder)
+	assert.Equal(t, true, hasConflicts(input))
+	assert.Equal(t, expected, builder.String())
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"blitznote.com/src/semver/v3"
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// computeNodeEngines determines the Node.js engine ranges under which each
+// supported range of a target is tested. It also returns the lowest Node.js
+// version under which any of the target's ranges are tested. The returned
+// minimum is empty when none of the tests constrain the Node.js version.
+func computeNodeEngines(info PkgInfo) ([]RangeNodeEngine, string) {
+	engines := make([]RangeNodeEngine, 0)
+	var minNode *semver.Version
+
+	for _, test := range info.Tests {
+		engine := RangeNodeEngine{Versions: test.Versions, Node: test.Node}
+		if slices.Contains(engines, engine) == false {
+			engines = append(engines, engine)
+		}
+
+		if test.Node == "" {
+			continue
+		}
+		ranges, err := parseRangeExpression(test.Node)
+		if err != nil {
+			continue
+		}
+		for _, r := range ranges {
+			lower := r.GetLowerBoundary()
+			if lower == nil {
+				continue
+			}
+			if minNode == nil || lower.Less(*minNode) {
+				minNode = lower
+			}
+		}
+	}
+
+	if minNode == nil {
+		return engines, ""
+	}
+	return engines, minNode.String()
+}
+
+// nodeMajorSupported determines if any release of the given Node.js major
+// version satisfies the engine range, i.e. if the range intersects
+// `[major.0.0, major+1.0.0)`. An empty engine range is satisfied by every
+// major version.
+func nodeMajorSupported(engine string, major int) bool {
+	if engine == "" {
+		return true
+	}
+
+	ranges, err := parseRangeExpression(engine)
+	if err != nil {
+		return false
+	}
+
+	first, _ := semver.NewVersion([]byte(fmt.Sprintf("%d.0.0", major)))
+	next, _ := semver.NewVersion([]byte(fmt.Sprintf("%d.0.0", major+1)))
+	for _, r := range ranges {
+		lower := r.GetLowerBoundary()
+		upper := r.GetUpperBoundary()
+		if lower != nil && lower.Less(next) == false {
+			// The range starts at, or after, the next major version.
+			continue
+		}
+		if upper != nil && upper.Less(first) == true {
+			// The range ends before the major version.
+			continue
+		}
+		if r.Contains(first) == true {
+			return true
+		}
+		// The range starts within the major version, e.g. `>=18.17 <18.19`.
+		if lower != nil && first.Less(*lower) == true {
+			if upper == nil || lower.Less(*upper) == true || r.Contains(*lower) == true {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseNodeMajors parses a comma separated list of Node.js major versions,
+// e.g. "20,22,24".
+func parseNodeMajors(input string) ([]int, error) {
+	results := make([]int, 0)
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimPrefix(strings.TrimSpace(part), "v")
+		if part == "" {
+			continue
+		}
+		major, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Node.js major version `%s`: %w", part, err)
+		}
+		results = append(results, major)
+	}
+	slices.Sort(results)
+	return results, nil
+}
+
+// renderNodeMatrix renders a Markdown section that indicates, for each tested
+// range of each module, which Node.js major versions the range is tested
+// under.
+func renderNodeMatrix(data []ReleaseData, majors []int, writer io.Writer) {
+	outputTable := table.NewWriter()
+
+	header := table.Row{"Package name", "Version range"}
+	for _, major := range majors {
+		header = append(header, fmt.Sprintf("Node.js %d", major))
+	}
+	outputTable.AppendHeader(header)
+
+	for _, info := range data {
+		for _, engine := range info.NodeEngines {
+			row := table.Row{fmt.Sprintf("`%s`", info.Name), engine.Versions}
+			for _, major := range majors {
+				row = append(row, aiCompatBoolEmoji(nodeMajorSupported(engine.Node, major)))
+			}
+			outputTable.AppendRow(row)
+		}
+	}
+
+	io.WriteString(writer, "## Node.js compatibility\n\n")
+	io.WriteString(
+		writer,
+		heredoc.Doc(`
+			The following matrix shows the Node.js versions under which each tested
+			range of a module is verified.
+		`),
+	)
+	io.WriteString(writer, "\n")
+	io.WriteString(writer, outputTable.RenderMarkdown())
+	io.WriteString(writer, "\n")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func Test_computeNodeEngines(t *testing.T) {
+	t.Run("collects unique ranges and minimum", func(t *testing.T) {
+		info := PkgInfo{
+			Tests: []TargetTest{
+				{Versions: ">=2.0.0", Node: ">=18"},
+				{Versions: ">=2.0.0", Node: ">=18"},
+				{Versions: ">=1.0.0 <2.0.0", Node: ">=16.10 <20"},
+			},
+		}
+		engines, minNode := computeNodeEngines(info)
+		expected := []RangeNodeEngine{
+			{Versions: ">=2.0.0", Node: ">=18"},
+			{Versions: ">=1.0.0 <2.0.0", Node: ">=16.10 <20"},
+		}
+		assert.Equal(t, expected, engines)
+		assert.Equal(t, "16.10.0", minNode)
+	})
+
+	t.Run("returns empty minimum for unconstrained tests", func(t *testing.T) {
+		info := PkgInfo{Tests: []TargetTest{{Versions: ">=1.0.0"}}}
+		engines, minNode := computeNodeEngines(info)
+		assert.Equal(t, []RangeNodeEngine{{Versions: ">=1.0.0"}}, engines)
+		assert.Equal(t, "", minNode)
+	})
+}
+
+func Test_nodeMajorSupported(t *testing.T) {
+	assert.Equal(t, true, nodeMajorSupported("", 16))
+	assert.Equal(t, true, nodeMajorSupported(">=18", 20))
+	assert.Equal(t, false, nodeMajorSupported(">=18", 16))
+	assert.Equal(t, true, nodeMajorSupported(">=18.12", 18))
+	assert.Equal(t, false, nodeMajorSupported(">=16 <20", 20))
+	assert.Equal(t, true, nodeMajorSupported("16 || >=20", 22))
+	assert.Equal(t, false, nodeMajorSupported("bogus", 22))
+
+	t.Run("ranges within a single major", func(t *testing.T) {
+		assert.Equal(t, true, nodeMajorSupported(">=18.17 <18.19", 18))
+		assert.Equal(t, false, nodeMajorSupported(">=18.17 <18.19", 17))
+		assert.Equal(t, false, nodeMajorSupported(">=18.17 <18.19", 19))
+		assert.Equal(t, true, nodeMajorSupported("^20.5.0 <20.9", 20))
+		assert.Equal(t, false, nodeMajorSupported("^20.5.0 <20.9", 22))
+		assert.Equal(t, true, nodeMajorSupported(">18.17.0 <18.19", 18))
+		assert.Equal(t, true, nodeMajorSupported("~18.2", 18))
+		assert.Equal(t, false, nodeMajorSupported("<18", 18))
+	})
+}
+
+func Test_parseNodeMajors(t *testing.T) {
+	found, err := parseNodeMajors("22, v20,24,")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{20, 22, 24}, found)
+
+	found, err = parseNodeMajors("20,lts")
+	assert.Nil(t, found)
+	assert.ErrorContains(t, err, "invalid Node.js major version `lts`")
+}
+
+func Test_renderNodeMatrix(t *testing.T) {
+	input := []ReleaseData{
+		{
+			Name: "foo",
+			NodeEngines: []RangeNodeEngine{
+				{Versions: ">=2.0.0", Node: ">=20"},
+				{Versions: "<2.0.0", Node: ">=18 <22"},
+			},
+		},
+	}
+
+	builder := strings.Builder{}
+	renderNodeMatrix(input, []int{18, 20, 22}, &builder)
+	found := builder.String()
+	assert.Contains(t, found, "## Node.js compatibility")
+	assert.Contains(t, found, "| Package name | Version range | Node.js 18 | Node.js 20 | Node.js 22 |")
+	assert.Contains(t, found, "| `foo` | >=2.0.0 | ❌ | ✅ | ✅ |")
+	assert.Contains(t, found, "| `foo` | <2.0.0 | ✅ | ✅ | ❌ |")
+}
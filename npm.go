@@ -8,6 +8,9 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
+
+	"blitznote.com/src/semver/v3"
 )
 
 type NpmClient struct {
@@ -29,6 +32,39 @@ type NpmDetailedPackage struct {
 	// Time is a map where the key is a version string and the value is
 	// the date and time that version was published to the registry.
 	Time map[string]rfc3339.DateTime `json:"time"`
+
+	// DistTags is a map where the key is a dist-tag, e.g. `latest` or `next`,
+	// and the value is the version string the tag points to.
+	DistTags map[string]string `json:"dist-tags"`
+}
+
+// LowestSatisfying finds the lowest published version that satisfies the
+// given range. Prerelease versions are not considered. The second return
+// value is `false` when no published version satisfies the range.
+func (ndp *NpmDetailedPackage) LowestSatisfying(r semver.Range) (string, bool) {
+	var lowest *semver.Version
+	for versionString := range ndp.Versions {
+		if strings.Contains(versionString, "-") {
+			continue
+		}
+
+		version, err := semver.NewVersion([]byte(versionString))
+		if err != nil {
+			continue
+		}
+		if r.Contains(version) == false {
+			continue
+		}
+
+		if lowest == nil || version.Less(*lowest) {
+			lowest = &version
+		}
+	}
+
+	if lowest == nil {
+		return "", false
+	}
+	return lowest.String(), true
 }
 
 type NpmClientOption func(*NpmClient)
@@ -71,10 +107,11 @@ func WithLogger(logger *slog.Logger) NpmClientOption {
 // GetDetailedInfo gets the full detailed information about a package from the
 // NPM registry.
 func (nc *NpmClient) GetDetailedInfo(packageName string) (*NpmDetailedPackage, error) {
-	nc.log.Debug("getting detailed info for " + packageName)
+	nc.log.Debug("getting detailed info", logKeyPackage, packageName)
+	start := time.Now()
 	req, err := http.NewRequest(
 		http.MethodGet,
-		fmt.Sprintf("%s/%s", nc.baseUrl, packageName),
+		nc.detailedInfoUrl(packageName),
 		nil,
 	)
 	if err != nil {
@@ -86,6 +123,12 @@ func (nc *NpmClient) GetDetailedInfo(packageName string) (*NpmDetailedPackage, e
 		return nil, err
 	}
 	defer res.Body.Close()
+	nc.log.Debug(
+		"registry responded",
+		logKeyPackage, packageName,
+		"status", res.StatusCode,
+		logKeyDuration, time.Since(start),
+	)
 
 	if res.StatusCode != 200 {
 		return nil, fmt.Errorf("expected response code 200 but got %d: %s", res.StatusCode, res.Status)
@@ -102,10 +145,11 @@ func (nc *NpmClient) GetDetailedInfo(packageName string) (*NpmDetailedPackage, e
 
 // GetLatest retrieves the latest version string for the given package.
 func (nc *NpmClient) GetLatest(packageName string) (string, error) {
-	nc.log.Debug("getting latest version for " + packageName)
+	nc.log.Debug("getting latest version", logKeyPackage, packageName)
+	start := time.Now()
 	req, err := http.NewRequest(
 		http.MethodGet,
-		fmt.Sprintf("%s/%s/latest", nc.baseUrl, packageName),
+		nc.latestUrl(packageName),
 		nil,
 	)
 	if err != nil {
@@ -117,6 +161,12 @@ func (nc *NpmClient) GetLatest(packageName string) (string, error) {
 		return "", err
 	}
 	defer res.Body.Close()
+	nc.log.Debug(
+		"registry responded",
+		logKeyPackage, packageName,
+		"status", res.StatusCode,
+		logKeyDuration, time.Since(start),
+	)
 
 	if res.StatusCode != 200 {
 		return "", fmt.Errorf("expected response code 200 but got %d: %s", res.StatusCode, res.Status)
@@ -130,3 +180,15 @@ func (nc *NpmClient) GetLatest(packageName string) (string, error) {
 
 	return body.Version, nil
 }
+
+// detailedInfoUrl is the URL of the detailed information about a package,
+// see [NpmClient.GetDetailedInfo].
+func (nc *NpmClient) detailedInfoUrl(packageName string) string {
+	return fmt.Sprintf("%s/%s", nc.baseUrl, packageName)
+}
+
+// latestUrl is the URL of the latest version of a package, see
+// [NpmClient.GetLatest].
+func (nc *NpmClient) latestUrl(packageName string) string {
+	return fmt.Sprintf("%s/%s/latest", nc.baseUrl, packageName)
+}
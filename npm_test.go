@@ -1,6 +1,7 @@
 package main
 
 import (
+	"blitznote.com/src/semver/v3"
 	"errors"
 	"github.com/jsumners/go-rfc3339"
 	"github.com/stretchr/testify/assert"
@@ -143,3 +144,36 @@ func Test_GetLatest(t *testing.T) {
 		assert.Equal(t, "1.0.0", result)
 	})
 }
+
+func Test_LowestSatisfying(t *testing.T) {
+	pkg := &NpmDetailedPackage{
+		Versions: map[string]any{
+			"1.0.0":       nil,
+			"2.0.1":       nil,
+			"2.1.0":       nil,
+			"3.0.0-beta1": nil,
+			"3.0.1":       nil,
+		},
+	}
+
+	t.Run("finds lowest published version in range", func(t *testing.T) {
+		r, _ := semver.NewRange([]byte(">=2.0.0"))
+		found, ok := pkg.LowestSatisfying(r)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "2.0.1", found)
+	})
+
+	t.Run("skips prerelease versions", func(t *testing.T) {
+		r, _ := semver.NewRange([]byte(">=2.2.0"))
+		found, ok := pkg.LowestSatisfying(r)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "3.0.1", found)
+	})
+
+	t.Run("reports no match", func(t *testing.T) {
+		r, _ := semver.NewRange([]byte(">=4.0.0"))
+		found, ok := pkg.LowestSatisfying(r)
+		assert.Equal(t, false, ok)
+		assert.Empty(t, found)
+	})
+}
@@ -17,9 +17,109 @@ var spaceBeforeDigitRegex = regexp.MustCompile(`([<>=]{1})(\s+)(\d)`)
 const max_range = "<=999.999.999"
 
 type PkgInfo struct {
-	Name            string
-	MinVersion      string
+	Name       string
+	MinVersion string
+
+	// MinVersionRange is the normalized range string from which MinVersion
+	// was derived. It is used to resolve MinVersion against the versions that
+	// have actually been published to the registry.
+	MinVersionRange string
+
 	MinAgentVersion string
+
+	// Tests is the set of supported versioned test blocks that exercise
+	// the target.
+	Tests []TargetTest
+
+	// Incompatible is the set of ranges that versioned test blocks declare
+	// as not supported.
+	Incompatible []IncompatibleRange
+
+	// Warnings describes problems found with the target's data that do not
+	// prevent it from being included in the report.
+	Warnings []string
+
+	// Trace records how MinVersion was derived. It is reported with the
+	// sources of the module when tracing is enabled.
+	Trace *DerivationTrace
+}
+
+// TargetTest describes a versioned test block as it applies to a specific
+// target.
+type TargetTest struct {
+	// Versions is the raw range string the test block installs.
+	Versions string `json:"versions"`
+
+	// Samples is the number of versions within the range that the versioned
+	// test runner will test. A value of `0` indicates all versions.
+	Samples int `json:"samples"`
+
+	// Node is the Node.js engine range the test block is run under. When the
+	// test block does not specify one, the range of the `package.json` is
+	// used.
+	Node string `json:"node"`
+
+	// Files is the list of test files the test block runs.
+	Files []string `json:"files"`
+}
+
+// RangeCandidate is a range, declared by a versioned test block, that was
+// considered when determining the minimum supported version of a target.
+type RangeCandidate struct {
+	// Test is the index of the test block within the `package.json`.
+	Test int
+
+	// Files is the list of test files the test block runs.
+	Files []string
+
+	// Versions is the raw range string the test block installs.
+	Versions string
+
+	// Normalized is the set of pieces of Versions, split on `||`, after
+	// [normalizeRangeString] has rewritten them.
+	Normalized []string
+
+	// Selected is the piece that [processRangeStrings] found to be the lowest.
+	Selected string
+
+	// Winner indicates the candidate supplied the minimum supported version.
+	Winner bool
+}
+
+// DerivationTrace records how the minimum supported version of a target was
+// derived from a versioned test `package.json`.
+type DerivationTrace struct {
+	// MinSupported is the `minSupported` value declared by the target. When
+	// it is set, the tests are not considered.
+	MinSupported string
+
+	// Candidates is the set of ranges considered, in the order they were
+	// considered.
+	Candidates []RangeCandidate
+
+	// MinVersionRange is the range the minimum supported version was derived
+	// from.
+	MinVersionRange string
+
+	// LowerBoundary is the lower boundary of MinVersionRange.
+	LowerBoundary string
+
+	// Responses is the set of values read from registry responses while
+	// building the release data, in the order they were read.
+	Responses []RegistryResponse
+}
+
+// RegistryResponse records a value that was read from a registry response.
+type RegistryResponse struct {
+	// Url is the URL that was requested.
+	Url string
+
+	// Field is the field of the response the value was read from, e.g.
+	// `version` or `time["1.2.3"]`.
+	Field string
+
+	// Value is the value that was read.
+	Value string
 }
 
 // parsePackage parses a versioned test `package.json` into the components
@@ -28,58 +128,149 @@ type PkgInfo struct {
 // of that module, and the minimum version of the agent that supports the
 // module.
 func parsePackage(pkg *VersionedTestPackageJson) ([]PkgInfo, error) {
-	var lastVersion *semver.Range
-	targets := pkg.Targets
-
 	results := make([]PkgInfo, 0)
-	for _, target := range targets {
-		if target.MinSupported == "" {
-			version, err := findMinimumSupported(target, pkg.Tests)
-			if err != nil {
-				return nil, err
-			}
-			lastVersion = version
-		} else {
-			version, err := semver.NewRange([]byte(target.MinSupported))
-			if err != nil {
-				return nil, fmt.Errorf("%s: could not parse minSupported string '%s'", pkg.Name, target.MinSupported)
-			}
-			lastVersion = &version
+	for _, target := range pkg.Targets {
+		trace, err := traceTarget(target, pkg)
+		if err != nil {
+			return nil, err
 		}
 
-		if lastVersion == nil {
-			if target.Name != "" {
-				return nil, fmt.Errorf("%s (%s): %w", pkg.Name, target.Name, ErrTargetMissing)
-			} else {
-				return nil, fmt.Errorf("%s: %w", pkg.Name, ErrTargetMissing)
+		var warnings []string
+		if target.MinSupported != "" {
+			mismatch := checkMinSupported(target, pkg.Tests)
+			if mismatch != "" {
+				warnings = append(warnings, mismatch)
 			}
 		}
 
-		minVersion := lastVersion.GetLowerBoundary()
-		if minVersion == nil {
-			// This happens when the version is set to "latest" (or "*").
-			v, _ := semver.NewVersion([]byte("0.0.0"))
-			minVersion = &v
-		}
-
 		pkgInfo := PkgInfo{
 			Name:            target.Name,
-			MinVersion:      minVersion.String(),
+			MinVersion:      trace.LowerBoundary,
+			MinVersionRange: trace.MinVersionRange,
 			MinAgentVersion: target.MinAgentVersion,
+			Tests:           collectTargetTests(target, pkg),
+			Incompatible:    collectIncompatibleRanges(target, pkg.Tests),
+			Warnings:        warnings,
+			Trace:           trace,
 		}
 		results = append(results, pkgInfo)
-		lastVersion = nil
 	}
 
 	return results, nil
 }
 
+// traceTarget derives the minimum supported version of a target, recording
+// how it was derived. It is the derivation performed by [parsePackage], so
+// that `explain` describes exactly what the report is generated from.
+func traceTarget(target Target, pkg *VersionedTestPackageJson) (*DerivationTrace, error) {
+	trace := &DerivationTrace{MinSupported: target.MinSupported}
+
+	var version *semver.Range
+	if target.MinSupported == "" {
+		found, rangeString, candidates, err := traceMinimumSupported(target, pkg.Tests)
+		if err != nil {
+			return nil, err
+		}
+		version = found
+		trace.Candidates = candidates
+		trace.MinVersionRange = rangeString
+	} else {
+		declared, err := semver.NewRange([]byte(target.MinSupported))
+		if err != nil {
+			return nil, fmt.Errorf("%s: could not parse minSupported string '%s'", pkg.Name, target.MinSupported)
+		}
+		version = &declared
+		trace.MinVersionRange = target.MinSupported
+	}
+	if version == nil {
+		if target.Name != "" {
+			return nil, fmt.Errorf("%s (%s): %w", pkg.Name, target.Name, ErrTargetMissing)
+		}
+		return nil, fmt.Errorf("%s: %w", pkg.Name, ErrTargetMissing)
+	}
+
+	// A range without a lower boundary, i.e. "latest" (or "*"), is treated as
+	// "0.0.0".
+	trace.LowerBoundary = "0.0.0"
+	if lower := version.GetLowerBoundary(); lower != nil {
+		trace.LowerBoundary = lower.String()
+	}
+	return trace, nil
+}
+
+// checkMinSupported compares a target's declared `minSupported` value with
+// the minimum version covered by the tests. When the two disagree, a
+// description of the mismatch is returned. An empty string is returned when
+// they agree, or when no tests cover the target.
+func checkMinSupported(target Target, tests []TestDescription) string {
+	declared, err := semver.NewRange([]byte(target.MinSupported))
+	if err != nil {
+		return ""
+	}
+
+	tested, testedString, err := findMinimumSupported(target, tests)
+	if err != nil || tested == nil || testedString == max_range {
+		return ""
+	}
+
+	zero, _ := semver.NewVersion([]byte("0.0.0"))
+	declaredLower := declared.GetLowerBoundary()
+	if declaredLower == nil {
+		declaredLower = &zero
+	}
+	testedLower := tested.GetLowerBoundary()
+	if testedLower == nil {
+		testedLower = &zero
+	}
+
+	if declaredLower.Less(*testedLower) {
+		return fmt.Sprintf(
+			"declared minSupported `%s` is lower than the minimum tested version `%s`",
+			target.MinSupported,
+			testedLower.String(),
+		)
+	}
+	if testedLower.Less(*declaredLower) {
+		return fmt.Sprintf(
+			"declared minSupported `%s` is higher than the minimum tested version `%s`",
+			target.MinSupported,
+			testedLower.String(),
+		)
+	}
+	return ""
+}
+
+// splitAgentVersion splits a target's `minAgentVersion` value into the name
+// of the package that provides the instrumentation and the version of that
+// package. A plain version string, e.g. `1.2.3`, is provided by the `newrelic`
+// package, while a string like `@newrelic/foo@1.2.3` is provided by
+// `@newrelic/foo`.
+func splitAgentVersion(input string) (string, string) {
+	idx := strings.LastIndex(input, "@")
+	if idx <= 0 {
+		return "newrelic", input
+	}
+	return input[0:idx], input[idx+1:]
+}
+
 // findMinimumSupported iterates through a set of versioned test descriptors
 // to find the minimum version of the target that is covered by the tests.
-func findMinimumSupported(target Target, tests []TestDescription) (*semver.Range, error) {
+// The normalized range string of the found range is returned alongside it.
+func findMinimumSupported(target Target, tests []TestDescription) (*semver.Range, string, error) {
+	version, rangeString, _, err := traceMinimumSupported(target, tests)
+	return version, rangeString, err
+}
+
+// traceMinimumSupported is [findMinimumSupported], but it additionally
+// returns every range that was considered, in the order they were
+// considered, so that the result can be explained.
+func traceMinimumSupported(target Target, tests []TestDescription) (*semver.Range, string, []RangeCandidate, error) {
 	var lastVersion *semver.Range
+	var lastRangeString string
+	candidates := make([]RangeCandidate, 0)
+	winner := -1
 
-	for _, test := range tests {
+	for i, test := range tests {
 		if test.Supported == false {
 			continue
 		}
@@ -87,13 +278,7 @@ func findMinimumSupported(target Target, tests []TestDescription) (*semver.Range
 		// We need to find the minimum version of the target module by looking
 		// through the dependencies list and the inspecting the semver range
 		// strings associated with it.
-		for key, val := range test.Dependencies {
-			if key != target.Name {
-				continue
-			}
-
-			var currentVersion semver.Range
-
+		for _, val := range registryDependencies(test, target.Name) {
 			// The semver library does not parse strings like `>1.0.0 <2.0.0 || >3.0.0`.
 			// So we need to split it up and normalize the pieces into range strings
 			// it can understand.
@@ -103,57 +288,134 @@ func findMinimumSupported(target Target, tests []TestDescription) (*semver.Range
 				rangeStrings[k] = normalizeRangeString(v)
 			}
 
-			currentVersion, err := processRangeStrings(rangeStrings)
+			currentVersion, currentRangeString, err := processRangeStrings(rangeStrings)
 			if err != nil {
-				return nil, fmt.Errorf("`%s` => `%s`: %w", target, val.Versions, err)
+				return nil, "", candidates, fmt.Errorf("`%s` => `%s`: %w", target, val.Versions, err)
 			}
+			candidates = append(candidates, RangeCandidate{
+				Test:       i,
+				Files:      test.Files,
+				Versions:   val.Versions,
+				Normalized: rangeStrings,
+				Selected:   currentRangeString,
+			})
 
 			if lastVersion == nil {
 				lastVersion = &currentVersion
+				lastRangeString = currentRangeString
+				winner = len(candidates) - 1
 				continue
 			}
 
 			if isRangeLower(currentVersion, *lastVersion) == true {
 				lastVersion = &currentVersion
+				lastRangeString = currentRangeString
+				winner = len(candidates) - 1
 			}
 		}
 	}
 
-	return lastVersion, nil
+	if winner >= 0 {
+		candidates[winner].Winner = true
+	}
+	return lastVersion, lastRangeString, candidates, nil
+}
+
+// collectTargetTests gathers the supported test blocks that exercise the
+// target.
+func collectTargetTests(target Target, pkg *VersionedTestPackageJson) []TargetTest {
+	results := make([]TargetTest, 0)
+	for _, test := range pkg.Tests {
+		if test.Supported == false {
+			continue
+		}
+
+		node := test.Engines.Node
+		if node == "" {
+			node = pkg.Engines.Node
+		}
+
+		for _, dep := range registryDependencies(test, target.Name) {
+			results = append(results, TargetTest{
+				Versions: dep.Versions,
+				Samples:  dep.Samples,
+				Node:     node,
+				Files:    test.Files,
+			})
+		}
+	}
+	return results
+}
+
+// collectIncompatibleRanges gathers the ranges of the target from test blocks
+// that are marked as not supported and explain why via a comment.
+func collectIncompatibleRanges(target Target, tests []TestDescription) []IncompatibleRange {
+	var results []IncompatibleRange
+	for _, test := range tests {
+		if test.Supported == true || test.Comment == "" {
+			continue
+		}
+
+		for _, dep := range registryDependencies(test, target.Name) {
+			results = append(results, IncompatibleRange{
+				Versions: dep.Versions,
+				Comment:  test.Comment,
+			})
+		}
+	}
+	return results
+}
+
+// parseRangeExpression parses a full range string, i.e. one that may include
+// `||` separated ranges, into the set of ranges it represents.
+func parseRangeExpression(input string) ([]semver.Range, error) {
+	rangeStrings := strings.Split(input, "||")
+	results := make([]semver.Range, 0, len(rangeStrings))
+	for _, rangeString := range rangeStrings {
+		normalized := normalizeRangeString(rangeString)
+		r, err := semver.NewRange([]byte(normalized))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse version string `%s`: %w", normalized, err)
+		}
+		results = append(results, r)
+	}
+	return results, nil
 }
 
 // processRangeStrings iterates a slice of semver range strings and returns
-// the range with the lowest minimum version. The provided range strings
-// should be normalized.
-func processRangeStrings(rangeStrings []string) (semver.Range, error) {
+// the range with the lowest minimum version, along with the string it was
+// parsed from. The provided range strings should be normalized.
+func processRangeStrings(rangeStrings []string) (semver.Range, string, error) {
 	var result semver.Range
 
 	if len(rangeStrings) == 1 {
 		r, err := semver.NewRange([]byte(rangeStrings[0]))
 		if err != nil {
-			return result, fmt.Errorf("failed to parse version string `%s`: %w", rangeStrings[0], err)
+			return result, "", fmt.Errorf("failed to parse version string `%s`: %w", rangeStrings[0], err)
 		}
 		result = r
-		return result, nil
+		return result, rangeStrings[0], nil
 	}
 
 	ranges := make([]semver.Range, 0)
 	for _, rangeString := range rangeStrings {
 		r, err := semver.NewRange([]byte(rangeString))
 		if err != nil {
-			return result, fmt.Errorf("failed to parse version string `%s`: %w", rangeString, err)
+			return result, "", fmt.Errorf("failed to parse version string `%s`: %w", rangeString, err)
 		}
 		ranges = append(ranges, r)
 	}
 
 	result = ranges[0]
-	for _, r := range ranges[1:] {
+	resultString := rangeStrings[0]
+	for i, r := range ranges[1:] {
 		if isRangeLower(r, result) == true {
 			result = r
+			resultString = rangeStrings[i+1]
 		}
 	}
 
-	return result, nil
+	return result, resultString, nil
 }
 
 // normalizeRangeString massages range strings into a format that the
@@ -13,6 +13,12 @@ func testPkg(t *testing.T, jsonFile string, expected []PkgInfo) {
 	pkgJson := readJsonFile(t, jsonFile)
 	found, err := parsePackage(&pkgJson)
 	assert.Nil(t, err)
+	for i := range found {
+		require.NotNil(t, found[i].Trace)
+		assert.Equal(t, found[i].MinVersion, found[i].Trace.LowerBoundary)
+		assert.Equal(t, found[i].MinVersionRange, found[i].Trace.MinVersionRange)
+		found[i].Trace = nil
+	}
 	assert.Equal(t, expected, found)
 }
 
@@ -30,7 +36,12 @@ func Test_ParsePackage(t *testing.T) {
 		testPkg(t, "testdata/out-of-order-ranges.json", []PkgInfo{{
 			Name:            "foo",
 			MinVersion:      "1.5.0",
+			MinVersionRange: ">=1.5.0",
 			MinAgentVersion: "0.0.0",
+			Tests: []TargetTest{
+				{Versions: ">=4.0.0", Samples: 2, Node: ">=16", Files: []string{}},
+				{Versions: ">=1.5.0", Node: ">=16", Files: []string{}},
+			},
 		}})
 	})
 
@@ -38,7 +49,17 @@ func Test_ParsePackage(t *testing.T) {
 		testPkg(t, "testdata/versioned/elastic/package.json", []PkgInfo{{
 			Name:            "@elastic/elasticsearch",
 			MinVersion:      "7.16.0",
+			MinVersionRange: ">=7.16.0",
 			MinAgentVersion: "1.2.3",
+			Tests: []TargetTest{
+				{Versions: ">=7.16.0", Node: ">=16", Files: []string{"elasticsearch.tap.js"}},
+			},
+			Incompatible: []IncompatibleRange{
+				{
+					Versions: "7.13.0",
+					Comment:  "Used to assert our instrumentation does not get loaded on old versions.",
+				},
+			},
 		}})
 	})
 
@@ -46,7 +67,20 @@ func Test_ParsePackage(t *testing.T) {
 		testPkg(t, "testdata/versioned/langchain/package.json", []PkgInfo{{
 			Name:            "@langchain/core",
 			MinVersion:      "0.1.17",
+			MinVersionRange: ">=0.1.17",
 			MinAgentVersion: "2.1.3",
+			Tests: []TargetTest{
+				{
+					Versions: ">=0.1.17",
+					Node:     ">=18",
+					Files: []string{
+						"tools.tap.js",
+						"runnables.tap.js",
+						"runnables-streaming.tap.js",
+						"vectorstore.tap.js",
+					},
+				},
+			},
 		}})
 	})
 
@@ -54,7 +88,37 @@ func Test_ParsePackage(t *testing.T) {
 		testPkg(t, "testdata/versioned/mongodb/package.json", []PkgInfo{{
 			Name:            "mongodb",
 			MinVersion:      "2.1.0",
+			MinVersionRange: ">=2.1 <4.0.0",
 			MinAgentVersion: "1.0.0",
+			Tests: []TargetTest{
+				{
+					Versions: ">=2.1 < 4.0.0",
+					Samples:  2,
+					Node:     ">=16",
+					Files: []string{
+						"legacy/bulk.tap.js",
+						"legacy/cursor.tap.js",
+						"legacy/db.tap.js",
+						"legacy/find.tap.js",
+						"legacy/index.tap.js",
+						"legacy/misc.tap.js",
+						"legacy/update.tap.js",
+					},
+				},
+				{
+					Versions: ">=4.1.4",
+					Node:     ">=16",
+					Files: []string{
+						"bulk.tap.js",
+						"collection-find.tap.js",
+						"collection-index.tap.js",
+						"collection-misc.tap.js",
+						"collection-update.tap.js",
+						"cursor.tap.js",
+						"db.tap.js",
+					},
+				},
+			},
 		}})
 	})
 
@@ -63,22 +127,41 @@ func Test_ParsePackage(t *testing.T) {
 			{
 				Name:            "koa",
 				MinVersion:      "2.0.0",
+				MinVersionRange: ">=2.0.0",
 				MinAgentVersion: "3.2.0",
+				Tests: []TargetTest{
+					{Versions: ">=2.0.0", Samples: 5, Node: ">=16", Files: []string{"koa.tap.js", "code-level-metrics.tap.js"}},
+					{Versions: ">=2.0.0", Samples: 5, Node: ">=16", Files: []string{"koa-router.tap.js", "code-level-metrics.tap.js"}},
+					{Versions: ">=2.0.0", Samples: 5, Node: ">=16", Files: []string{"scoped-koa-router.tap.js", "code-level-metrics.tap.js"}},
+					{Versions: ">=2.0.0", Samples: 5, Node: ">=16", Files: []string{"koa-route.tap.js"}},
+				},
 			},
 			{
 				Name:            "koa-route",
 				MinVersion:      "3.0.0",
+				MinVersionRange: ">=3.0.0",
 				MinAgentVersion: "3.2.0",
+				Tests: []TargetTest{
+					{Versions: ">=3.0.0", Samples: 5, Node: ">=16", Files: []string{"koa-route.tap.js"}},
+				},
 			},
 			{
 				Name:            "koa-router",
 				MinVersion:      "7.1.0",
+				MinVersionRange: ">=7.1.0",
 				MinAgentVersion: "3.2.0",
+				Tests: []TargetTest{
+					{Versions: ">=7.1.0", Samples: 5, Node: ">=16", Files: []string{"koa-router.tap.js", "code-level-metrics.tap.js"}},
+				},
 			},
 			{
 				Name:            "@koa/router",
 				MinVersion:      "8.0.0",
+				MinVersionRange: ">=8.0.0",
 				MinAgentVersion: "3.2.0",
+				Tests: []TargetTest{
+					{Versions: ">=8.0.0", Samples: 5, Node: ">=16", Files: []string{"scoped-koa-router.tap.js", "code-level-metrics.tap.js"}},
+				},
 			},
 		})
 	})
@@ -96,7 +179,11 @@ func Test_ParsePackage(t *testing.T) {
 		testPkg(t, "testdata/ordered-or-range.json", []PkgInfo{{
 			Name:            "foo",
 			MinVersion:      "1.0.0",
+			MinVersionRange: ">=1.0.0 <2.0.0",
 			MinAgentVersion: "1.0.0",
+			Tests: []TargetTest{
+				{Versions: ">=1.0.0 <2.0.0 || >=3.0.0 <4.0.0 || >=5.0.0"},
+			},
 		}})
 	})
 
@@ -104,7 +191,11 @@ func Test_ParsePackage(t *testing.T) {
 		testPkg(t, "testdata/unordered-or-range.json", []PkgInfo{{
 			Name:            "foo",
 			MinVersion:      "1.0.0",
+			MinVersionRange: ">=1.0.0 <2.0.0",
 			MinAgentVersion: "1.0.0",
+			Tests: []TargetTest{
+				{Versions: ">=3.0.0 <4.0.0 || >=1.0.0 <2.0.0 || >=5.0.0"},
+			},
 		}})
 	})
 
@@ -112,7 +203,11 @@ func Test_ParsePackage(t *testing.T) {
 		testPkg(t, "testdata/latest-version.json", []PkgInfo{{
 			Name:            "foo",
 			MinVersion:      "0.0.0",
+			MinVersionRange: max_range,
 			MinAgentVersion: "1.0.0",
+			Tests: []TargetTest{
+				{Versions: "latest"},
+			},
 		}})
 	})
 
@@ -120,41 +215,96 @@ func Test_ParsePackage(t *testing.T) {
 		testPkg(t, "testdata/min-supported.json", []PkgInfo{{
 			Name:            "foo",
 			MinVersion:      "0.3.0",
+			MinVersionRange: "0.3.0",
 			MinAgentVersion: "1.0.0",
+			Tests:           []TargetTest{},
 		}})
 	})
 }
 
+func Test_checkMinSupported(t *testing.T) {
+	pkg := readJsonFile(t, "testdata/min-supported-mismatch.json")
+
+	found := checkMinSupported(pkg.Targets[0], pkg.Tests)
+	assert.Equal(t, "declared minSupported `1.0.0` is lower than the minimum tested version `2.0.0`", found)
+
+	found = checkMinSupported(pkg.Targets[1], pkg.Tests)
+	assert.Equal(t, "declared minSupported `3.0.0` is higher than the minimum tested version `2.0.0`", found)
+
+	found = checkMinSupported(pkg.Targets[2], pkg.Tests)
+	assert.Empty(t, found)
+
+	found = checkMinSupported(Target{Name: "qux", MinSupported: "1.0.0"}, pkg.Tests)
+	assert.Empty(t, found)
+
+	infos, err := parsePackage(&pkg)
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(infos[0].Warnings))
+	assert.Equal(t, 1, len(infos[1].Warnings))
+	assert.Nil(t, infos[2].Warnings)
+}
+
+func Test_collectIncompatibleRanges(t *testing.T) {
+	tests := []TestDescription{
+		{
+			Supported:    false,
+			Comment:      "broken",
+			Dependencies: DependenciesBlock{"foo": DependencyBlock{Versions: "1.2.0"}},
+		},
+		{
+			Supported:    false,
+			Dependencies: DependenciesBlock{"foo": DependencyBlock{Versions: "1.3.0"}},
+		},
+		{
+			Supported:    false,
+			Comment:      "other target",
+			Dependencies: DependenciesBlock{"bar": DependencyBlock{Versions: "1.0.0"}},
+		},
+		{
+			Supported:    true,
+			Comment:      "supported",
+			Dependencies: DependenciesBlock{"foo": DependencyBlock{Versions: ">=1.0.0"}},
+		},
+	}
+
+	found := collectIncompatibleRanges(Target{Name: "foo"}, tests)
+	assert.Equal(t, []IncompatibleRange{{Versions: "1.2.0", Comment: "broken"}}, found)
+}
+
 func Test_processRangeStrings(t *testing.T) {
 	t.Run("errors for single invalid range", func(t *testing.T) {
-		result, err := processRangeStrings([]string{"> 1.0.0 "})
+		result, rangeString, err := processRangeStrings([]string{"> 1.0.0 "})
 		assert.Empty(t, result)
+		assert.Empty(t, rangeString)
 		assert.ErrorContains(t, err, "failed to parse version string `> 1.0.0 `")
 	})
 
 	t.Run("errors for invalid range in multiple ranges", func(t *testing.T) {
-		result, err := processRangeStrings([]string{
+		result, rangeString, err := processRangeStrings([]string{
 			">1.0.0",
 			" < 3.0.0 ",
 		})
 		assert.Empty(t, result)
+		assert.Empty(t, rangeString)
 		assert.ErrorContains(t, err, "failed to parse version string ` < 3.0.0 `")
 	})
 
 	t.Run("processes a single range string", func(t *testing.T) {
-		result, err := processRangeStrings([]string{">1.0.0"})
+		result, rangeString, err := processRangeStrings([]string{">1.0.0"})
 		assert.Nil(t, err)
 		assert.Equal(t, "1.0.0", result.GetLowerBoundary().String())
+		assert.Equal(t, ">1.0.0", rangeString)
 	})
 
 	t.Run("processes multiple strings and returns the correct one", func(t *testing.T) {
-		result, err := processRangeStrings([]string{
+		result, rangeString, err := processRangeStrings([]string{
 			">1.0.0",
 			">0.1.0 <1.0.0",
 			">3.0.0",
 		})
 		assert.Nil(t, err)
 		assert.Equal(t, "0.1.0", result.GetLowerBoundary().String())
+		assert.Equal(t, ">0.1.0 <1.0.0", rangeString)
 	})
 }
 
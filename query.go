@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"blitznote.com/src/semver/v3"
+)
+
+var ErrPackageNotFound = errors.New("package is not instrumented")
+
+var ErrInvalidQueryVersion = errors.New("not an exact version")
+
+// QueryResult is the compatibility data of a single module, and, when a
+// version was queried, whether that version is supported.
+type QueryResult struct {
+	Name string `json:"name"`
+
+	// SupportedRange is the union of the ranges tested by the supported
+	// versioned test blocks.
+	SupportedRange      string              `json:"supportedRange"`
+	MinSupportedVersion string              `json:"minSupportedVersion"`
+	TestedUpTo          string              `json:"testedUpTo"`
+	LatestVersion       string              `json:"latestVersion"`
+	MinAgentVersion     string              `json:"minAgentVersion"`
+	NodeEngines         []RangeNodeEngine   `json:"nodeEngines"`
+	KnownIncompatible   []IncompatibleRange `json:"knownIncompatible,omitempty"`
+
+	// Version is the queried version. The remaining fields are only set when
+	// a version was queried.
+	Version     string `json:"version,omitempty"`
+	Supported   *bool  `json:"supported,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// splitPackageVersion splits a string like `koa@2.14.1` or `@scope/pkg@^1`
+// into the package name and the version. The version is empty when the
+// string does not include one.
+func splitPackageVersion(input string) (string, string) {
+	// An `@` at the start is part of a scoped package name, not the version
+	// separator.
+	idx := strings.Index(input[min(1, len(input)):], "@") + 1
+	if idx <= 0 {
+		return input, ""
+	}
+	return input[0:idx], input[idx+1:]
+}
+
+// runQuery writes the compatibility data of the queried module, given as
+// `name` or `name@version`, to the writer. An error wrapping
+// [ErrPackageNotFound] is returned if the module is not instrumented, and an
+// error wrapping [ErrInvalidQueryVersion] is returned if the version is not
+// an exact version, e.g. a range like `^2` or a dist-tag like `latest`.
+func runQuery(query string, data []ReleaseData, format string, writer io.Writer) error {
+	name, version := splitPackageVersion(query)
+	if version != "" {
+		if _, err := semver.NewVersion([]byte(version)); err != nil {
+			return fmt.Errorf("%w: `%s`, query an exact version, e.g. `%s@1.2.3`", ErrInvalidQueryVersion, version, name)
+		}
+	}
+	idx := slices.IndexFunc(data, func(info ReleaseData) bool { return info.Name == name })
+	if idx < 0 {
+		return fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+	}
+
+	result := buildQueryResult(data[idx], version)
+	switch format {
+	case outputFormatJson:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	default:
+		renderQueryResult(result, writer)
+	}
+	return nil
+}
+
+// buildQueryResult builds the query result for a module. When a version is
+// given, the result answers whether that version is supported.
+func buildQueryResult(info ReleaseData, version string) QueryResult {
+	ranges := make([]string, 0, len(info.Tests))
+	for _, test := range info.Tests {
+		ranges = appendUnique(ranges, strings.TrimSpace(test.Versions))
+	}
+
+	result := QueryResult{
+		Name:                info.Name,
+		SupportedRange:      strings.Join(ranges, " || "),
+		MinSupportedVersion: info.MinSupportedVersion,
+		TestedUpTo:          testedUpToVersion(info),
+		LatestVersion:       info.LatestVersion,
+		MinAgentVersion:     info.MinAgentVersion,
+		NodeEngines:         info.NodeEngines,
+		KnownIncompatible:   info.KnownIncompatible,
+	}
+	if version == "" {
+		return result
+	}
+
+	status, details := checkInstalledVersion(version, info)
+	supported := status == appStatusSupported
+	result.Version = version
+	result.Supported = &supported
+	result.Status = status
+	result.Explanation = details
+	return result
+}
+
+// renderQueryResult renders the query result as plain text.
+func renderQueryResult(result QueryResult, writer io.Writer) {
+	lines := [][2]string{
+		{"Supported range", result.SupportedRange},
+		{"Minimum supported version", result.MinSupportedVersion},
+		{"Tested up to", result.TestedUpTo},
+		{"Latest published version", result.LatestVersion},
+		{"Minimum agent version", result.MinAgentVersion},
+	}
+	for _, engine := range result.NodeEngines {
+		node := engine.Node
+		if node == "" {
+			node = "any"
+		}
+		lines = append(lines, [2]string{"Node.js for " + engine.Versions, node})
+	}
+	for _, incompatible := range result.KnownIncompatible {
+		lines = append(lines, [2]string{"Incompatible " + incompatible.Versions, incompatible.Comment})
+	}
+
+	io.WriteString(writer, result.Name+"\n")
+	for _, line := range lines {
+		io.WriteString(writer, fmt.Sprintf("  %-27s %s\n", line[0]+":", line[1]))
+	}
+
+	if result.Supported == nil {
+		return
+	}
+	answer := "no"
+	if *result.Supported == true {
+		answer = "yes"
+	}
+	explanation := result.Status
+	if result.Explanation != "" {
+		explanation = fmt.Sprintf("%s, %s", explanation, result.Explanation)
+	}
+	io.WriteString(
+		writer,
+		fmt.Sprintf("\nIs %s@%s supported? %s (%s)\n", result.Name, result.Version, answer, explanation),
+	)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_splitPackageVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		name    string
+		version string
+	}{
+		{input: "koa", name: "koa", version: ""},
+		{input: "koa@2.14.1", name: "koa", version: "2.14.1"},
+		{input: "@scope/pkg", name: "@scope/pkg", version: ""},
+		{input: "@scope/pkg@^1", name: "@scope/pkg", version: "^1"},
+		{input: "", name: "", version: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			name, version := splitPackageVersion(tc.input)
+			assert.Equal(t, tc.name, name)
+			assert.Equal(t, tc.version, version)
+		})
+	}
+}
+
+func Test_runQuery(t *testing.T) {
+	data := []ReleaseData{{
+		Name:                "koa",
+		MinSupportedVersion: "2.0.0",
+		LatestVersion:       "2.16.1",
+		MinAgentVersion:     "3.2.0",
+		TestedVersions:      []string{"2.0.0", "2.16.1"},
+		NodeEngines:         []RangeNodeEngine{{Versions: ">=2.0.0", Node: ">=18"}},
+		Tests: []TargetTest{
+			{Versions: ">=2.0.0", Node: ">=18"},
+			{Versions: ">=2.0.0", Node: ">=18"},
+			{Versions: "1.7.0"},
+		},
+		KnownIncompatible: []IncompatibleRange{{Versions: "<2.0.0", Comment: "Generators are not supported."}},
+	}}
+
+	t.Run("renders the module data", func(t *testing.T) {
+		builder := &strings.Builder{}
+		err := runQuery("koa", data, outputFormatMarkdown, builder)
+		require.Nil(t, err)
+
+		expected := heredoc.Doc(`
+			koa
+			  Supported range:            >=2.0.0 || 1.7.0
+			  Minimum supported version:  2.0.0
+			  Tested up to:               2.16.1
+			  Latest published version:   2.16.1
+			  Minimum agent version:      3.2.0
+			  Node.js for >=2.0.0:        >=18
+			  Incompatible <2.0.0:        Generators are not supported.
+		`)
+		assert.Equal(t, expected, builder.String())
+	})
+
+	t.Run("answers whether a version is supported", func(t *testing.T) {
+		builder := &strings.Builder{}
+		err := runQuery("koa@2.14.1", data, outputFormatMarkdown, builder)
+		require.Nil(t, err)
+		assert.Contains(t, builder.String(), "Is koa@2.14.1 supported? yes (supported, instrumented since 3.2.0)")
+
+		builder.Reset()
+		err = runQuery("koa@1.7.0", data, outputFormatMarkdown, builder)
+		require.Nil(t, err)
+		assert.Contains(
+			t,
+			builder.String(),
+			"Is koa@1.7.0 supported? no (known incompatible, `<2.0.0` is not supported: Generators are not supported.)",
+		)
+	})
+
+	t.Run("versions between tested ranges are not supported", func(t *testing.T) {
+		gapData, err := readReleaseDataFile("testdata/check-app-gap-report.json")
+		require.Nil(t, err)
+
+		builder := &strings.Builder{}
+		err = runQuery("mongodb@3.1.0", gapData, outputFormatMarkdown, builder)
+		require.Nil(t, err)
+		assert.Contains(t, builder.String(), "Supported range:            >=2 <3 || >=4")
+		assert.Contains(
+			t,
+			builder.String(),
+			"Is mongodb@3.1.0 supported? no (untested, not within a tested range: `>=2 <3`, `>=4`)",
+		)
+	})
+
+	t.Run("renders json", func(t *testing.T) {
+		builder := &strings.Builder{}
+		err := runQuery("koa@3.0.0", data, outputFormatJson, builder)
+		require.Nil(t, err)
+		assert.Contains(t, builder.String(), `"supported": false`)
+		assert.Contains(t, builder.String(), `"status": "newer than tested"`)
+		assert.Contains(t, builder.String(), `"testedUpTo": "2.16.1"`)
+	})
+
+	t.Run("rejects versions that are not exact", func(t *testing.T) {
+		for _, query := range []string{"koa@^2", "koa@latest"} {
+			builder := &strings.Builder{}
+			err := runQuery(query, data, outputFormatMarkdown, builder)
+			assert.ErrorIs(t, err, ErrInvalidQueryVersion, query)
+			assert.ErrorContains(t, err, "query an exact version, e.g. `koa@1.2.3`")
+			assert.Empty(t, builder.String())
+		}
+	})
+
+	t.Run("reports modules that are not instrumented", func(t *testing.T) {
+		err := runQuery("left-pad@1.0.0", data, outputFormatMarkdown, &strings.Builder{})
+		assert.ErrorIs(t, err, ErrPackageNotFound)
+		assert.ErrorContains(t, err, "left-pad")
+	})
+}
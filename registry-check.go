@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// publishStatus describes the state of a single version of a package within
+// the registry.
+type publishStatus int
+
+const (
+	versionPublished publishStatus = iota
+	versionDeprecated
+	versionUnpublished
+	versionMissing
+)
+
+// VersionStatus determines the registry state of a specific version of the
+// package. A version that is listed in the publish times, but is no longer
+// present in the set of versions, has been unpublished. When the version is
+// deprecated, the deprecation message is also returned.
+func (ndp *NpmDetailedPackage) VersionStatus(version string) (publishStatus, string) {
+	manifest, found := ndp.Versions[version]
+	if found == false {
+		if _, published := ndp.Time[version]; published == true {
+			return versionUnpublished, ""
+		}
+		return versionMissing, ""
+	}
+
+	fields, isObject := manifest.(map[string]any)
+	if isObject == false {
+		return versionPublished, ""
+	}
+	switch deprecated := fields["deprecated"].(type) {
+	case string:
+		if deprecated != "" {
+			return versionDeprecated, deprecated
+		}
+	case bool:
+		if deprecated == true {
+			return versionDeprecated, ""
+		}
+	}
+	return versionPublished, ""
+}
+
+// packumentCache retains the detailed registry information for packages that
+// are referenced by many targets, e.g. the `newrelic` package, so that they
+// are only requested once.
+type packumentCache struct {
+	npm     *NpmClient
+	lock    sync.Mutex
+	entries map[string]*packumentCacheEntry
+}
+
+// packumentCacheEntry holds the result of the single request for a package.
+// Concurrent lookups of the same package wait on `once`, while lookups of
+// other packages proceed.
+type packumentCacheEntry struct {
+	once sync.Once
+	pkg  *NpmDetailedPackage
+	err  error
+}
+
+func newPackumentCache(npm *NpmClient) *packumentCache {
+	return &packumentCache{
+		npm:     npm,
+		entries: make(map[string]*packumentCacheEntry),
+	}
+}
+
+// Get returns the detailed registry information for a package, requesting it
+// from the registry if it has not been previously retrieved. Failed requests
+// are cached as well. The cache is only locked while finding the entry of the
+// package, so that requests for different packages run concurrently.
+func (pc *packumentCache) Get(packageName string) (*NpmDetailedPackage, error) {
+	pc.lock.Lock()
+	entry, found := pc.entries[packageName]
+	if found == false {
+		entry = &packumentCacheEntry{}
+		pc.entries[packageName] = entry
+	}
+	pc.lock.Unlock()
+
+	entry.once.Do(func() {
+		entry.pkg, entry.err = pc.npm.GetDetailedInfo(packageName)
+	})
+	return entry.pkg, entry.err
+}
+
+// validateRegistryVersions verifies that the versions referenced by a target
+// are real versions within the registry. The declared minimum supported
+// version is checked against the target package itself, unless the target
+// only supports the latest version, and the
+// `minAgentVersion` is checked against the `newrelic` package, or the plugin
+// package named within it. A message is returned for every version that does
+// not exist, has been unpublished, or has been deprecated. The messages are
+// only logged, as the state of the registry must not change the rendered
+// report.
+func validateRegistryVersions(
+	info PkgInfo,
+	pkg *NpmDetailedPackage,
+	packuments *packumentCache,
+) []string {
+	results := make([]string, 0)
+
+	if info.MinVersion != "" && info.MinVersionRange != max_range {
+		problem := describeVersionStatus(pkg, info.Name, info.MinVersion)
+		if problem != "" {
+			results = append(results, "minimum supported version "+problem)
+		}
+	}
+
+	if info.MinAgentVersion != "" {
+		agentPackage, agentVersion := splitAgentVersion(info.MinAgentVersion)
+		agentPkg, err := packuments.Get(agentPackage)
+		if err != nil {
+			results = append(results, fmt.Sprintf(
+				"could not verify minAgentVersion `%s`: %s",
+				info.MinAgentVersion,
+				err,
+			))
+		} else if problem := describeVersionStatus(agentPkg, agentPackage, agentVersion); problem != "" {
+			results = append(results, "minAgentVersion "+problem)
+		}
+	}
+
+	return results
+}
+
+// describeVersionStatus renders a description of the problem with a version
+// of a package. An empty string is returned for versions that are published
+// and not deprecated.
+func describeVersionStatus(pkg *NpmDetailedPackage, packageName string, version string) string {
+	status, message := pkg.VersionStatus(version)
+	switch status {
+	case versionMissing:
+		return fmt.Sprintf("`%s` is not a published version of `%s`", version, packageName)
+	case versionUnpublished:
+		return fmt.Sprintf("`%s` of `%s` has been unpublished", version, packageName)
+	case versionDeprecated:
+		if message == "" {
+			return fmt.Sprintf("`%s` of `%s` is deprecated", version, packageName)
+		}
+		return fmt.Sprintf("`%s` of `%s` is deprecated: %s", version, packageName, message)
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jsumners/go-rfc3339"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VersionStatus(t *testing.T) {
+	dt, _ := rfc3339.NewDateTimeFromString("2024-05-03T13:00:00.000-04:00")
+	pkg := &NpmDetailedPackage{
+		Versions: map[string]any{
+			"1.0.0": map[string]any{},
+			"1.1.0": map[string]any{"deprecated": "use 1.2.0"},
+			"1.2.0": nil,
+		},
+		Time: map[string]rfc3339.DateTime{
+			"1.0.0": dt,
+			"1.0.1": dt,
+			"1.1.0": dt,
+			"1.2.0": dt,
+		},
+	}
+
+	tests := []struct {
+		version         string
+		expectedStatus  publishStatus
+		expectedMessage string
+	}{
+		{version: "1.0.0", expectedStatus: versionPublished},
+		{version: "1.0.1", expectedStatus: versionUnpublished},
+		{version: "1.1.0", expectedStatus: versionDeprecated, expectedMessage: "use 1.2.0"},
+		{version: "1.2.0", expectedStatus: versionPublished},
+		{version: "9.0.0", expectedStatus: versionMissing},
+	}
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			status, message := pkg.VersionStatus(tc.version)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedMessage, message)
+		})
+	}
+}
+
+func Test_packumentCache(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests += 1
+		io.WriteString(res, `{"versions":{"1.0.0":{}}}`)
+	}))
+	defer ts.Close()
+	packuments := newPackumentCache(NewNpmClient(WithBaseUrl(ts.URL)))
+
+	first, err := packuments.Get("newrelic")
+	assert.Nil(t, err)
+	second, err := packuments.Get("newrelic")
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, requests)
+	assert.Same(t, first, second)
+
+	t.Run("requests different packages concurrently", func(t *testing.T) {
+		barrier := sync.WaitGroup{}
+		barrier.Add(2)
+		ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			barrier.Done()
+			waited := make(chan struct{})
+			go func() {
+				barrier.Wait()
+				close(waited)
+			}()
+			select {
+			case <-waited:
+				io.WriteString(res, `{"versions":{"1.0.0":{}}}`)
+			case <-time.After(2 * time.Second):
+				res.WriteHeader(http.StatusGatewayTimeout)
+			}
+		}))
+		defer ts.Close()
+		packuments := newPackumentCache(NewNpmClient(WithBaseUrl(ts.URL)))
+
+		wg := sync.WaitGroup{}
+		errs := make([]error, 2)
+		for i, name := range []string{"newrelic", "@newrelic/foo"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[i] = packuments.Get(name)
+			}()
+		}
+		wg.Wait()
+		assert.Nil(t, errs[0])
+		assert.Nil(t, errs[1])
+	})
+}
+
+func Test_validateRegistryVersions(t *testing.T) {
+	dt, _ := rfc3339.NewDateTimeFromString("2024-05-03T13:00:00.000-04:00")
+	ts := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/newrelic":
+			io.WriteString(res, `{"versions":{"11.0.0":{},"11.1.0":{"deprecated":"broken"}}}`)
+		case "/@newrelic/foo":
+			io.WriteString(res, `{"versions":{"2.0.0":{}}}`)
+		default:
+			res.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+	packuments := newPackumentCache(NewNpmClient(WithBaseUrl(ts.URL)))
+	pkg := &NpmDetailedPackage{
+		Versions: map[string]any{"1.0.0": map[string]any{}},
+		Time:     map[string]rfc3339.DateTime{"1.0.0": dt, "0.9.0": dt},
+	}
+
+	t.Run("accepts published versions", func(t *testing.T) {
+		info := PkgInfo{Name: "pkg", MinVersion: "1.0.0", MinAgentVersion: "11.0.0"}
+		result := validateRegistryVersions(info, pkg, packuments)
+		assert.Empty(t, result)
+	})
+
+	t.Run("reports problems with the declared minimum supported version", func(t *testing.T) {
+		info := PkgInfo{Name: "pkg", MinVersion: "0.9.0", MinAgentVersion: "11.0.0"}
+		result := validateRegistryVersions(info, pkg, packuments)
+		assert.Equal(t, []string{"minimum supported version `0.9.0` of `pkg` has been unpublished"}, result)
+
+		info.MinVersion = "0.1.0"
+		result = validateRegistryVersions(info, pkg, packuments)
+		assert.Equal(t, []string{"minimum supported version `0.1.0` is not a published version of `pkg`"}, result)
+	})
+
+	t.Run("skips targets that only support the latest version", func(t *testing.T) {
+		info := PkgInfo{Name: "pkg", MinVersion: "0.0.0", MinVersionRange: max_range, MinAgentVersion: "11.0.0"}
+		result := validateRegistryVersions(info, pkg, packuments)
+		assert.Empty(t, result)
+	})
+
+	t.Run("reports problems with the agent version", func(t *testing.T) {
+		info := PkgInfo{Name: "pkg", MinAgentVersion: "11.1.0"}
+		result := validateRegistryVersions(info, pkg, packuments)
+		assert.Equal(t, []string{"minAgentVersion `11.1.0` of `newrelic` is deprecated: broken"}, result)
+	})
+
+	t.Run("checks plugin agent versions", func(t *testing.T) {
+		info := PkgInfo{Name: "pkg", MinAgentVersion: "@newrelic/foo@3.0.0"}
+		result := validateRegistryVersions(info, pkg, packuments)
+		assert.Equal(t, []string{"minAgentVersion `3.0.0` is not a published version of `@newrelic/foo`"}, result)
+	})
+
+	t.Run("reports registry failures", func(t *testing.T) {
+		info := PkgInfo{Name: "pkg", MinAgentVersion: "@newrelic/bar@1.0.0"}
+		result := validateRegistryVersions(info, pkg, packuments)
+		assert.Len(t, result, 1)
+		assert.Contains(t, result[0], "could not verify minAgentVersion `@newrelic/bar@1.0.0`")
+	})
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+const (
+	phaseClone    = "clone"
+	phaseParse    = "parse"
+	phaseRegistry = "registry"
+	phaseRender   = "render"
+)
+
+// runPhases is the order in which phases are listed in the error summary.
+var runPhases = []string{phaseClone, phaseParse, phaseRegistry, phaseRender}
+
+const (
+	exitCodeFailure        = 1
+	exitCodePartialFailure = 2
+)
+
+// ErrPartialFailure indicates that a document was generated, but some of the
+// data that should be included in it could not be processed.
+var ErrPartialFailure = errors.New("some data could not be processed")
+
+// ErrTotalFailure indicates that a document could not be generated at all.
+var ErrTotalFailure = errors.New("no data could be processed")
+
+// RunError is an error that was encountered, but not acted upon, during a
+// specific phase of a run.
+type RunError struct {
+	Phase string
+
+	// Subject identifies what was being processed when the error occurred,
+	// e.g. a repository URL or a file path.
+	Subject string
+
+	Err error
+}
+
+func (re RunError) Error() string {
+	if re.Subject == "" {
+		return re.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", re.Subject, re.Err)
+}
+
+func (re RunError) Unwrap() error {
+	return re.Err
+}
+
+// runErrors collects the errors encountered during a run so that they may be
+// summarized once the run is complete. It is safe for concurrent use.
+type runErrors struct {
+	lock   sync.Mutex
+	errors []RunError
+}
+
+// Add records an error for the given phase.
+func (re *runErrors) Add(phase string, subject string, err error) {
+	re.lock.Lock()
+	defer re.lock.Unlock()
+	re.errors = append(re.errors, RunError{Phase: phase, Subject: subject, Err: err})
+}
+
+// Len returns the number of recorded errors.
+func (re *runErrors) Len() int {
+	re.lock.Lock()
+	defer re.lock.Unlock()
+	return len(re.errors)
+}
+
+// WriteSummary writes the recorded errors, grouped by phase, to the writer.
+// Nothing is written when no errors have been recorded.
+func (re *runErrors) WriteSummary(writer io.Writer) {
+	re.lock.Lock()
+	defer re.lock.Unlock()
+	if len(re.errors) == 0 {
+		return
+	}
+
+	io.WriteString(writer, fmt.Sprintf("%d error(s) occurred:\n", len(re.errors)))
+	for _, phase := range runPhases {
+		phaseErrors := slices.DeleteFunc(slices.Clone(re.errors), func(e RunError) bool {
+			return e.Phase != phase
+		})
+		if len(phaseErrors) == 0 {
+			continue
+		}
+
+		io.WriteString(writer, fmt.Sprintf("  %s (%d):\n", phase, len(phaseErrors)))
+		for _, e := range phaseErrors {
+			io.WriteString(writer, fmt.Sprintf("    - %s\n", e.Error()))
+		}
+	}
+}
+
+// exitCode maps an error returned by [Run] to the process exit code.
+func exitCode(err error) int {
+	if errors.Is(err, ErrPartialFailure) == true {
+		return exitCodePartialFailure
+	}
+	return exitCodeFailure
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_runErrors(t *testing.T) {
+	t.Run("writes nothing without errors", func(t *testing.T) {
+		runErrs := &runErrors{}
+		builder := strings.Builder{}
+		runErrs.WriteSummary(&builder)
+		assert.Equal(t, "", builder.String())
+	})
+
+	t.Run("groups errors by phase", func(t *testing.T) {
+		runErrs := &runErrors{}
+		runErrs.Add(phaseRegistry, "foo", errors.New("not found"))
+		runErrs.Add(phaseClone, "", errors.New("failed to clone repo"))
+		runErrs.Add(phaseRegistry, "bar", errors.New("timeout"))
+
+		expected := heredoc.Doc(`
+			3 error(s) occurred:
+			  clone (1):
+			    - failed to clone repo
+			  registry (2):
+			    - foo: not found
+			    - bar: timeout
+		`)
+		builder := strings.Builder{}
+		runErrs.WriteSummary(&builder)
+		assert.Equal(t, 3, runErrs.Len())
+		assert.Equal(t, expected, builder.String())
+	})
+}
+
+func Test_exitCode(t *testing.T) {
+	assert.Equal(t, exitCodePartialFailure, exitCode(fmt.Errorf("%w: 1 error(s)", ErrPartialFailure)))
+	assert.Equal(t, exitCodeFailure, exitCode(fmt.Errorf("%w: 1 error(s)", ErrTotalFailure)))
+	assert.Equal(t, exitCodeFailure, exitCode(errors.New("boom")))
+}
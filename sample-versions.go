@@ -0,0 +1,163 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"blitznote.com/src/semver/v3"
+)
+
+// publishedVersion pairs a parsed version with the version string as it is
+// listed in the registry.
+type publishedVersion struct {
+	raw     string
+	version semver.Version
+}
+
+// computeTestedVersions emulates the version selection performed by the
+// versioned test runner (`versioned-tests` from `@newrelic/test-utilities`)
+// for each of the target's test blocks. It returns the set of versions that
+// will be tested, and the set of published versions within the tested ranges
+// that will not be tested. Both sets are sorted in ascending order.
+func computeTestedVersions(
+	info PkgInfo,
+	pkg *NpmDetailedPackage,
+	latest string,
+	logger *slog.Logger,
+) ([]string, []string) {
+	published := sortedPublishedVersions(pkg)
+	tested := make(map[string]bool)
+	inRange := make(map[string]bool)
+
+	for _, test := range info.Tests {
+		candidates, err := versionsInRange(published, test.Versions, latest)
+		if err != nil {
+			logger.Warn(
+				"could not determine tested versions",
+				logKeyPackage, info.Name,
+				"range", test.Versions,
+				logKeyError, err,
+			)
+			continue
+		}
+
+		for _, candidate := range candidates {
+			inRange[candidate.raw] = true
+		}
+		for _, sample := range sampleVersions(latestPerMinor(candidates), test.Samples) {
+			tested[sample.raw] = true
+		}
+	}
+
+	testedVersions := make([]string, 0)
+	untestedVersions := make([]string, 0)
+	for _, v := range published {
+		if tested[v.raw] == true {
+			testedVersions = append(testedVersions, v.raw)
+		} else if inRange[v.raw] == true {
+			untestedVersions = append(untestedVersions, v.raw)
+		}
+	}
+
+	return testedVersions, untestedVersions
+}
+
+// sortedPublishedVersions returns all published, non-prerelease, versions
+// of a package in ascending order.
+func sortedPublishedVersions(pkg *NpmDetailedPackage) []publishedVersion {
+	results := make([]publishedVersion, 0, len(pkg.Versions))
+	for versionString := range pkg.Versions {
+		if strings.Contains(versionString, "-") {
+			continue
+		}
+
+		version, err := semver.NewVersion([]byte(versionString))
+		if err != nil {
+			continue
+		}
+		results = append(results, publishedVersion{raw: versionString, version: version})
+	}
+
+	slices.SortFunc(results, func(a publishedVersion, b publishedVersion) int {
+		switch {
+		case a.version.Less(b.version):
+			return -1
+		case b.version.Less(a.version):
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return results
+}
+
+// versionsInRange filters a sorted set of versions down to the ones that
+// satisfy a range string as it is written in a versioned test block. The
+// runner resolves `latest` to the single latest published version.
+func versionsInRange(versions []publishedVersion, rangeString string, latest string) ([]publishedVersion, error) {
+	if strings.TrimSpace(rangeString) == "latest" {
+		idx := slices.IndexFunc(versions, func(v publishedVersion) bool { return v.raw == latest })
+		if idx == -1 {
+			return []publishedVersion{}, nil
+		}
+		return versions[idx : idx+1], nil
+	}
+
+	ranges, err := parseRangeExpression(rangeString)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]publishedVersion, 0)
+	for _, v := range versions {
+		if slices.ContainsFunc(ranges, func(r semver.Range) bool { return r.Contains(v.version) }) {
+			results = append(results, v)
+		}
+	}
+	return results, nil
+}
+
+// latestPerMinor reduces a sorted set of versions to the latest patch
+// release of each minor release line. This matches the runner's default
+// `--minor` mode.
+func latestPerMinor(versions []publishedVersion) []publishedVersion {
+	results := make([]publishedVersion, 0)
+	for i, v := range versions {
+		if i == len(versions)-1 || minorLine(versions[i+1].raw) != minorLine(v.raw) {
+			results = append(results, v)
+		}
+	}
+	return results
+}
+
+// minorLine returns the `major.minor` portion of a version string.
+func minorLine(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// sampleVersions selects `samples` versions from a sorted set of versions in
+// the same manner as the runner: the first and last versions are always
+// included, and the remaining samples are spread evenly between them. A
+// `samples` value of `0` selects every version.
+func sampleVersions(versions []publishedVersion, samples int) []publishedVersion {
+	if samples <= 0 || samples >= len(versions) {
+		return versions
+	}
+	if samples == 1 {
+		return versions[len(versions)-1:]
+	}
+
+	results := make([]publishedVersion, 0, samples)
+	step := float64(len(versions)-1) / float64(samples-1)
+	for i := 0; i < samples; i += 1 {
+		idx := int(float64(i)*step + 0.5)
+		results = append(results, versions[idx])
+	}
+	return results
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func testPackument(versions ...string) *NpmDetailedPackage {
+	pkg := &NpmDetailedPackage{Versions: make(map[string]any)}
+	for _, v := range versions {
+		pkg.Versions[v] = nil
+	}
+	return pkg
+}
+
+func rawVersions(versions []publishedVersion) []string {
+	results := make([]string, 0, len(versions))
+	for _, v := range versions {
+		results = append(results, v.raw)
+	}
+	return results
+}
+
+func Test_sortedPublishedVersions(t *testing.T) {
+	pkg := testPackument("2.0.0", "1.10.0", "1.2.0", "3.0.0-rc.1", "1.2.1")
+	found := sortedPublishedVersions(pkg)
+	assert.Equal(t, []string{"1.2.0", "1.2.1", "1.10.0", "2.0.0"}, rawVersions(found))
+}
+
+func Test_versionsInRange(t *testing.T) {
+	versions := sortedPublishedVersions(testPackument("1.0.0", "1.5.0", "2.0.0", "3.0.0", "3.1.0"))
+
+	t.Run("filters ORed ranges", func(t *testing.T) {
+		found, err := versionsInRange(versions, ">=1.0.0 <2.0.0 || >= 3.1.0", "3.1.0")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1.0.0", "1.5.0", "3.1.0"}, rawVersions(found))
+	})
+
+	t.Run("resolves latest", func(t *testing.T) {
+		found, err := versionsInRange(versions, "latest", "3.0.0")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"3.0.0"}, rawVersions(found))
+	})
+
+	t.Run("returns error for bad range", func(t *testing.T) {
+		found, err := versionsInRange(versions, "bogus", "3.0.0")
+		assert.Nil(t, found)
+		assert.ErrorContains(t, err, "failed to parse version string")
+	})
+}
+
+func Test_latestPerMinor(t *testing.T) {
+	versions := sortedPublishedVersions(testPackument("1.0.0", "1.0.1", "1.1.0", "1.1.5", "2.0.0"))
+	found := latestPerMinor(versions)
+	assert.Equal(t, []string{"1.0.1", "1.1.5", "2.0.0"}, rawVersions(found))
+}
+
+func Test_sampleVersions(t *testing.T) {
+	versions := sortedPublishedVersions(testPackument(
+		"1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0", "1.5.0", "1.6.0",
+	))
+
+	t.Run("returns all versions when not sampling", func(t *testing.T) {
+		assert.Equal(t, 7, len(sampleVersions(versions, 0)))
+		assert.Equal(t, 7, len(sampleVersions(versions, 10)))
+	})
+
+	t.Run("returns latest for a single sample", func(t *testing.T) {
+		assert.Equal(t, []string{"1.6.0"}, rawVersions(sampleVersions(versions, 1)))
+	})
+
+	t.Run("spreads samples evenly", func(t *testing.T) {
+		found := sampleVersions(versions, 3)
+		assert.Equal(t, []string{"1.0.0", "1.3.0", "1.6.0"}, rawVersions(found))
+	})
+}
+
+func Test_computeTestedVersions(t *testing.T) {
+	pkg := testPackument("1.0.0", "1.0.1", "1.1.0", "1.2.0", "2.0.0", "2.1.0", "3.0.0")
+	info := PkgInfo{
+		Name: "foo",
+		Tests: []TargetTest{
+			{Versions: ">=1.0.0 <2.0.0", Samples: 2},
+			{Versions: "latest"},
+			{Versions: "bogus"},
+		},
+	}
+
+	collector := &logCollector{}
+	tested, untested := computeTestedVersions(info, pkg, "3.0.0", slog.New(slog.NewTextHandler(collector, nil)))
+	assert.Equal(t, []string{"1.0.1", "1.2.0", "3.0.0"}, tested)
+	assert.Equal(t, []string{"1.0.0", "1.1.0"}, untested)
+	assert.Equal(t, 1, len(collector.logs))
+	assert.Contains(t, collector.logs[0], "could not determine tested versions")
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var ErrUnsupportedSbom = errors.New("not a CycloneDX or SPDX JSON document")
+
+// cycloneDxBom represents the parts of a CycloneDX JSON document that are
+// needed to determine the installed packages.
+type cycloneDxBom struct {
+	BomFormat  string               `json:"bomFormat"`
+	Components []cycloneDxComponent `json:"components"`
+}
+
+type cycloneDxComponent struct {
+	Purl string `json:"purl"`
+
+	// Components are the components nested within the component, e.g. the
+	// bundled dependencies of a package.
+	Components []cycloneDxComponent `json:"components"`
+}
+
+// spdxDocument represents the parts of an SPDX JSON document that are
+// needed to determine the installed packages.
+type spdxDocument struct {
+	SpdxVersion string        `json:"spdxVersion"`
+	Packages    []spdxPackage `json:"packages"`
+}
+
+type spdxPackage struct {
+	ExternalRefs []spdxExternalRef `json:"externalRefs"`
+}
+
+type spdxExternalRef struct {
+	ReferenceType    string `json:"referenceType"`
+	ReferenceLocator string `json:"referenceLocator"`
+}
+
+// parseSbom parses the npm packages listed in a CycloneDX or SPDX JSON
+// document. Packages are identified by their package URLs, e.g.
+// `pkg:npm/%40scope/pkg@1.0.0`; components without an npm package URL are
+// omitted.
+func parseSbom(data []byte) ([]InstalledPackage, error) {
+	var header struct {
+		BomFormat   string `json:"bomFormat"`
+		SpdxVersion string `json:"spdxVersion"`
+	}
+	err := json.Unmarshal(data, &header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedSbom, err)
+	}
+
+	purls := make([]string, 0)
+	switch {
+	case header.BomFormat == "CycloneDX":
+		var bom cycloneDxBom
+		err = json.Unmarshal(data, &bom)
+		if err != nil {
+			return nil, err
+		}
+		var walk func(components []cycloneDxComponent)
+		walk = func(components []cycloneDxComponent) {
+			for _, component := range components {
+				purls = append(purls, component.Purl)
+				walk(component.Components)
+			}
+		}
+		walk(bom.Components)
+	case strings.HasPrefix(header.SpdxVersion, "SPDX-") == true:
+		var doc spdxDocument
+		err = json.Unmarshal(data, &doc)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range doc.Packages {
+			for _, ref := range pkg.ExternalRefs {
+				if ref.ReferenceType == "purl" {
+					purls = append(purls, ref.ReferenceLocator)
+				}
+			}
+		}
+	default:
+		return nil, ErrUnsupportedSbom
+	}
+
+	results := make([]InstalledPackage, 0, len(purls))
+	for _, purl := range purls {
+		name, version, ok := parseNpmPurl(purl)
+		if ok == true {
+			results = appendInstalled(results, name, version)
+		}
+	}
+	return results, nil
+}
+
+// parseNpmPurl parses an npm package URL, e.g. `pkg:npm/%40scope/pkg@1.0.0`,
+// into the package name and version. Qualifiers and subpaths are ignored.
+// The last return value is `false` when the URL is not an npm package URL, or
+// does not include a version.
+func parseNpmPurl(purl string) (string, string, bool) {
+	rest, found := strings.CutPrefix(purl, "pkg:npm/")
+	if found == false {
+		return "", "", false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+
+	idx := strings.LastIndex(rest, "@")
+	if idx <= 0 {
+		return "", "", false
+	}
+	name, err := url.PathUnescape(rest[0:idx])
+	if err != nil {
+		return "", "", false
+	}
+	version, err := url.PathUnescape(rest[idx+1:])
+	if err != nil {
+		return "", "", false
+	}
+	return name, version, true
+}
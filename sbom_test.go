@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseNpmPurl(t *testing.T) {
+	tests := []struct {
+		purl    string
+		name    string
+		version string
+		ok      bool
+	}{
+		{purl: "pkg:npm/koa@2.14.1", name: "koa", version: "2.14.1", ok: true},
+		{purl: "pkg:npm/%40scope/pkg@1.0.0", name: "@scope/pkg", version: "1.0.0", ok: true},
+		{purl: "pkg:npm/@scope/pkg@1.0.0", name: "@scope/pkg", version: "1.0.0", ok: true},
+		{purl: "pkg:npm/koa@2.14.1?foo=bar#lib/index.js", name: "koa", version: "2.14.1", ok: true},
+		{purl: "pkg:npm/koa", ok: false},
+		{purl: "pkg:pypi/requests@2.31.0", ok: false},
+		{purl: "pkg:npm/%zz@1.0.0", ok: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.purl, func(t *testing.T) {
+			name, version, ok := parseNpmPurl(tc.purl)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.name, name)
+			assert.Equal(t, tc.version, version)
+		})
+	}
+}
+
+func Test_parseSbom(t *testing.T) {
+	t.Run("reads a CycloneDX document", func(t *testing.T) {
+		found, err := readInstalledPackages("testdata/sbom/app.cdx.json")
+		require.Nil(t, err)
+		assert.Equal(t, []InstalledPackage{
+			{Name: "@scope/pkg", Version: "3.0.0"},
+			{Name: "debug", Version: "2.6.9"},
+			{Name: "express", Version: "4.18.2"},
+		}, found)
+	})
+
+	t.Run("reads an SPDX document", func(t *testing.T) {
+		found, err := readInstalledPackages("testdata/sbom/app.spdx.json")
+		require.Nil(t, err)
+		assert.Equal(t, []InstalledPackage{
+			{Name: "@scope/pkg", Version: "3.0.0"},
+			{Name: "koa", Version: "1.7.0"},
+		}, found)
+	})
+
+	t.Run("rejects other documents", func(t *testing.T) {
+		_, err := parseSbom([]byte(`{"name": "app"}`))
+		assert.ErrorIs(t, err, ErrUnsupportedSbom)
+
+		_, err = parseSbom([]byte(`[]`))
+		assert.ErrorIs(t, err, ErrUnsupportedSbom)
+	})
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/term"
+)
+
+// staleMinimumAge is the age of a minimum supported version release after
+// which the minimum is considered very old.
+const staleMinimumAge = 5 * 365 * 24 * time.Hour
+
+// terminalMinColumnWidth is the narrowest a column is wrapped to when the
+// table is fit to the width of the terminal.
+const terminalMinColumnWidth = 10
+
+var (
+	// latestUntestedColors highlights rows whose latest published version is
+	// not within any tested range.
+	latestUntestedColors = text.Colors{text.FgYellow}
+
+	// staleMinimumColors highlights rows whose minimum supported version was
+	// released a long time ago.
+	staleMinimumColors = text.Colors{text.FgHiBlack}
+)
+
+// terminalOptions controls how the report is rendered for a terminal.
+type terminalOptions struct {
+	// isTerminal indicates the output is written to an interactive terminal,
+	// in which case it is shown through a pager.
+	isTerminal bool
+
+	// colors enables highlighting of stale rows.
+	colors bool
+
+	// width is the width of the terminal. A value of `0` indicates the width
+	// is unknown, and the table is not constrained.
+	width int
+
+	// now is the time against which the age of releases is determined.
+	now time.Time
+}
+
+// detectTerminal determines the terminal options for writing to the file.
+// Colors are disabled when the file is not a terminal, or when the
+// `NO_COLOR` environment variable is set.
+func detectTerminal(file *os.File) terminalOptions {
+	opts := terminalOptions{now: time.Now()}
+
+	fd := int(file.Fd())
+	if term.IsTerminal(fd) == false {
+		return opts
+	}
+	opts.isTerminal = true
+
+	_, noColor := os.LookupEnv("NO_COLOR")
+	opts.colors = noColor == false
+
+	width, _, err := term.GetSize(fd)
+	if err == nil {
+		opts.width = width
+	}
+	return opts
+}
+
+// renderAsTerminal renders the collected data as a table meant to be read in
+// a terminal. The columns are the same as those of the Markdown table. Rows
+// whose latest published version is not tested, or whose minimum supported
+// version is very old, are highlighted.
+func renderAsTerminal(data []ReleaseData, opts terminalOptions, writer io.Writer) {
+	outputTable := releaseDataToTable(data)
+	outputTable.SetStyle(table.StyleLight)
+
+	stripCode := func(val any) string {
+		return strings.Trim(fmt.Sprint(val), "`")
+	}
+	columnConfigs := []table.ColumnConfig{
+		{Name: columHeaders["Name"], Transformer: stripCode},
+		{Name: columHeaders["MinAgentVersion"], Transformer: stripCode},
+	}
+	if opts.width > 0 {
+		columns := len(columHeaders)
+		// Each column is padded by a space on both sides, and is followed by a
+		// border, as is the first column.
+		columnWidth := max(terminalMinColumnWidth, (opts.width-3*columns-1)/columns)
+		for _, header := range columHeaders {
+			idx := slices.IndexFunc(columnConfigs, func(c table.ColumnConfig) bool { return c.Name == header })
+			if idx < 0 {
+				columnConfigs = append(columnConfigs, table.ColumnConfig{Name: header})
+				idx = len(columnConfigs) - 1
+			}
+			columnConfigs[idx].WidthMax = columnWidth
+			columnConfigs[idx].WidthMaxEnforcer = text.WrapSoft
+		}
+		outputTable.Style().Size.WidthMax = opts.width
+	}
+	outputTable.SetColumnConfigs(columnConfigs)
+
+	if opts.colors == true {
+		outputTable.SetRowPainter(table.RowPainterWithAttributes(func(row table.Row, attr table.RowAttributes) text.Colors {
+			info := data[attr.Number-1]
+			switch {
+			case isLatestUntested(info):
+				return latestUntestedColors
+			case isMinimumStale(info, opts.now):
+				return staleMinimumColors
+			default:
+				return nil
+			}
+		}))
+	}
+
+	io.WriteString(writer, outputTable.Render())
+	io.WriteString(writer, "\n")
+
+	legend := []string{
+		"* When package is not specified, support is within the newrelic package.",
+	}
+	if slices.ContainsFunc(data, func(d ReleaseData) bool { return len(d.Warnings) > 0 }) {
+		legend = append(legend, "⚠️ The compatibility data of the module could not be fully verified.")
+	}
+	if opts.colors == true {
+		legend = append(
+			legend,
+			latestUntestedColors.Sprint("The latest published version is not within a tested range."),
+			staleMinimumColors.Sprint("The minimum supported version was released more than five years ago."),
+		)
+	}
+	for _, line := range legend {
+		io.WriteString(writer, line+"\n")
+	}
+}
+
+// isLatestUntested determines if the latest published version of a module
+// is not within any of its tested ranges. Modules without tests are never
+// considered untested, as their ranges are unknown.
+func isLatestUntested(info ReleaseData) bool {
+	return len(info.Tests) > 0 && info.LatestVersion != "" && versionTested(info.LatestVersion, info) == false
+}
+
+// isMinimumStale determines if the minimum supported version of a module was
+// released more than [staleMinimumAge] before `now`.
+func isMinimumStale(info ReleaseData, now time.Time) bool {
+	released, err := time.Parse(time.DateOnly, info.MinSupportedVersionRelease)
+	if err != nil {
+		return false
+	}
+	return now.Sub(released) > staleMinimumAge
+}
+
+// writePaged writes the content to the writer through the pager named by
+// the `PAGER` environment variable, or `less` when it is not set. The
+// content is written directly when the pager cannot be found.
+func writePaged(content string, writer io.Writer) error {
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less", "-R", "-F", "-X"}
+	}
+
+	pagerPath, err := exec.LookPath(pager[0])
+	if err != nil {
+		_, err = io.WriteString(writer, content)
+		return err
+	}
+
+	cmd := exec.Command(pagerPath, pager[1:]...)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = writer
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_isLatestUntested(t *testing.T) {
+	info := ReleaseData{
+		LatestVersion: "3.0.0",
+		Tests:         []TargetTest{{Versions: ">=1.0.0 <2.0.0"}, {Versions: ">=2.0.0 <3.0.0"}},
+	}
+	assert.Equal(t, true, isLatestUntested(info))
+
+	info.Tests = append(info.Tests, TargetTest{Versions: ">=3.0.0"})
+	assert.Equal(t, false, isLatestUntested(info))
+
+	info.Tests = []TargetTest{{Versions: "latest"}}
+	assert.Equal(t, false, isLatestUntested(info))
+
+	info.Tests = nil
+	assert.Equal(t, false, isLatestUntested(info))
+}
+
+func Test_isMinimumStale(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, true, isMinimumStale(ReleaseData{MinSupportedVersionRelease: "2018-01-01"}, now))
+	assert.Equal(t, false, isMinimumStale(ReleaseData{MinSupportedVersionRelease: "2022-01-01"}, now))
+	assert.Equal(t, false, isMinimumStale(ReleaseData{MinSupportedVersionRelease: ""}, now))
+}
+
+func Test_renderAsTerminal(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	data := []ReleaseData{
+		{
+			Name:                       "foo",
+			MinSupportedVersion:        "1.0.0",
+			MinSupportedVersionRelease: "2024-01-01",
+			LatestVersion:              "3.0.0",
+			MinAgentVersion:            "@newrelic/foo@1.0.0",
+			Tests:                      []TargetTest{{Versions: ">=1.0.0 <3.0.0"}},
+		},
+		{
+			Name:                       "bar",
+			MinSupportedVersion:        "1.0.0",
+			MinSupportedVersionRelease: "2015-01-01",
+			LatestVersion:              "2.0.0",
+			MinAgentVersion:            "2.0.0",
+			Tests:                      []TargetTest{{Versions: ">=1.0.0"}},
+		},
+	}
+
+	t.Run("renders plain text", func(t *testing.T) {
+		builder := &strings.Builder{}
+		renderAsTerminal(data, terminalOptions{now: now}, builder)
+
+		found := builder.String()
+		assert.Contains(t, found, "│ foo          │")
+		assert.Contains(t, found, "│ @newrelic/foo@1.0.0 │")
+		assert.NotContains(t, found, "`")
+		assert.NotContains(t, found, "\x1b[")
+		assert.Contains(t, found, "* When package is not specified")
+	})
+
+	t.Run("highlights stale rows", func(t *testing.T) {
+		builder := &strings.Builder{}
+		renderAsTerminal(data, terminalOptions{colors: true, now: now}, builder)
+
+		found := builder.String()
+		assert.Contains(t, found, latestUntestedColors.Sprint(" foo          "))
+		assert.Contains(t, found, staleMinimumColors.Sprint(" bar          "))
+		assert.Contains(t, found, latestUntestedColors.Sprint("The latest published version is not within a tested range."))
+	})
+
+	t.Run("fits the terminal width", func(t *testing.T) {
+		builder := &strings.Builder{}
+		renderAsTerminal(data, terminalOptions{width: 80, now: now}, builder)
+
+		table, _, _ := strings.Cut(builder.String(), "\n*")
+		for _, line := range strings.Split(table, "\n") {
+			assert.LessOrEqual(t, len([]rune(line)), 80, line)
+		}
+	})
+}
+
+func Test_writePaged(t *testing.T) {
+	t.Run("writes through the pager", func(t *testing.T) {
+		t.Setenv("PAGER", "cat")
+		builder := &strings.Builder{}
+		err := writePaged("hello\n", builder)
+		assert.Nil(t, err)
+		assert.Equal(t, "hello\n", builder.String())
+	})
+
+	t.Run("writes directly without a pager", func(t *testing.T) {
+		t.Setenv("PAGER", "does-not-exist-pager")
+		builder := &strings.Builder{}
+		err := writePaged("hello\n", builder)
+		assert.Nil(t, err)
+		assert.Equal(t, "hello\n", builder.String())
+	})
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// helperDirNames lists the names of directories within versioned test
+// directories that hold shared test code rather than versioned tests.
+var helperDirNames = []string{"common", "fixtures", "helpers", "node_modules"}
+
+// discoveryOptions controls how versioned test `package.json` files are found
+// within a versioned tests directory.
+type discoveryOptions struct {
+	// recursive enables searching every level of the directory tree, instead
+	// of only the immediate subdirectories.
+	recursive bool
+
+	// include is a set of glob patterns. When not empty, only directories
+	// whose path, relative to the versioned tests directory, matches one of
+	// the patterns are considered.
+	include []string
+
+	// exclude is a set of glob patterns. Directories whose path, relative to
+	// the versioned tests directory, matches one of the patterns are skipped
+	// along with everything within them.
+	exclude []string
+}
+
+// iterateTestDir finds every versioned test `package.json` within a
+// versioned tests directory and sends the parsed results to the channel. If
+// the directory itself contains a `package.json`, that is the only result.
+// The channel is closed once the search is complete.
+func iterateTestDir(dir string, opts discoveryOptions, iterChan chan dirIterChan) {
+	defer close(iterChan)
+
+	pkgJsonPath := filepath.Join(dir, "package.json")
+	if _, err := os.Stat(pkgJsonPath); err == nil {
+		iterChan <- readTestPackage(dir, pkgJsonPath)
+		return
+	}
+
+	walkTestDir(dir, dir, opts, iterChan)
+}
+
+// walkTestDir searches the subdirectories of `dir` for versioned test
+// `package.json` files. Helper directories, and directories matching an
+// exclude pattern, are skipped without error. When not searching
+// recursively, a subdirectory without a `package.json` is reported as an
+// error. When searching recursively, a `package.json` that does not describe
+// versioned tests is skipped.
+func walkTestDir(root string, dir string, opts discoveryOptions, iterChan chan dirIterChan) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		iterChan <- dirIterChan{err: fmt.Errorf("failed to read directory `%s`: %w", dir, err)}
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() == false || isHelperDir(entry.Name()) == true {
+			continue
+		}
+
+		testDir := filepath.Join(dir, entry.Name())
+		relDir, _ := filepath.Rel(root, testDir)
+		relDir = filepath.ToSlash(relDir)
+		if matchesAnyGlob(opts.exclude, relDir) == true {
+			continue
+		}
+		included := len(opts.include) == 0 || matchesAnyGlob(opts.include, relDir)
+
+		pkgJsonPath := filepath.Join(testDir, "package.json")
+		_, statErr := os.Stat(pkgJsonPath)
+		switch {
+		case statErr == nil && included == true:
+			result := readTestPackage(relDir, pkgJsonPath)
+			if opts.recursive == false || result.err != nil || isVersionedTestPackage(result.pkg) == true {
+				iterChan <- result
+			}
+		case statErr != nil && included == true && opts.recursive == false:
+			iterChan <- dirIterChan{
+				name: relDir,
+				path: pkgJsonPath,
+				err:  fmt.Errorf("could not find package.json in `%s`: %w", testDir, statErr),
+			}
+		}
+
+		if opts.recursive == true {
+			walkTestDir(root, testDir, opts, iterChan)
+		}
+	}
+}
+
+// readTestPackage reads and parses a versioned test `package.json`.
+func readTestPackage(name string, pkgJsonPath string) dirIterChan {
+	pkgJsonFile, err := os.Open(pkgJsonPath)
+	if err != nil {
+		return dirIterChan{
+			name: name,
+			path: pkgJsonPath,
+			err:  fmt.Errorf("could not find package.json in `%s`: %w", filepath.Dir(pkgJsonPath), err),
+		}
+	}
+	defer pkgJsonFile.Close()
+
+	pkg, err := readPackageJson(pkgJsonFile)
+	if err != nil {
+		return dirIterChan{
+			name: name,
+			path: pkgJsonPath,
+			err:  fmt.Errorf("failed to read package.json for `%s`: %w", name, err),
+		}
+	}
+	return dirIterChan{name: name, path: pkgJsonPath, pkg: pkg}
+}
+
+// isHelperDir determines if a directory name is that of a helper directory,
+// or of a hidden directory, which never contain versioned tests.
+func isHelperDir(name string) bool {
+	if strings.HasPrefix(name, ".") == true || strings.HasPrefix(name, "_") == true {
+		return true
+	}
+	return slices.Contains(helperDirNames, name)
+}
+
+// isVersionedTestPackage determines if a `package.json` describes versioned
+// tests, as opposed to being a `package.json` of some test fixture.
+func isVersionedTestPackage(pkg *VersionedTestPackageJson) bool {
+	return len(pkg.Targets) > 0 || len(pkg.Tests) > 0
+}
+
+// matchesAnyGlob determines if the slash separated path matches any of the
+// glob patterns.
+func matchesAnyGlob(patterns []string, name string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		return matchGlob(pattern, name)
+	})
+}
+
+// matchGlob matches a slash separated path against a glob pattern. In
+// addition to the syntax supported by [path.Match], a `**` segment matches
+// any number of path segments.
+func matchGlob(pattern string, name string) bool {
+	return matchGlobSegments(
+		strings.Split(strings.Trim(pattern, "/"), "/"),
+		strings.Split(name, "/"),
+	)
+}
+
+func matchGlobSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i += 1 {
+				if matchGlobSegments(pattern[1:], name[i:]) == true {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		matched, err := path.Match(pattern[0], name[0])
+		if err != nil || matched == false {
+			return false
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+	return len(name) == 0
+}
+
+// splitList splits a comma separated list into its trimmed, non-empty,
+// items.
+func splitList(input string) []string {
+	results := make([]string, 0)
+	for _, item := range strings.Split(input, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			results = append(results, item)
+		}
+	}
+	return results
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collectTestDir gathers the package names and errors found while iterating
+// a versioned tests directory.
+func collectTestDir(dir string, opts discoveryOptions) ([]string, []string) {
+	names := make([]string, 0)
+	errs := make([]string, 0)
+
+	iterChan := make(chan dirIterChan)
+	go iterateTestDir(dir, opts, iterChan)
+	for result := range iterChan {
+		if result.err != nil {
+			errs = append(errs, result.name)
+			continue
+		}
+		names = append(names, result.pkg.Name)
+	}
+	return names, errs
+}
+
+func Test_iterateTestDir(t *testing.T) {
+	t.Run("reads a root package.json", func(t *testing.T) {
+		names, errs := collectTestDir("testdata/discovery/alpha", discoveryOptions{})
+		assert.Equal(t, []string{"alpha-tests"}, names)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("reads one level and skips helper directories", func(t *testing.T) {
+		names, errs := collectTestDir("testdata/discovery", discoveryOptions{})
+		assert.Equal(t, []string{"alpha-tests"}, names)
+		assert.Equal(t, []string{"group", "legacy"}, errs)
+	})
+
+	t.Run("reads recursively", func(t *testing.T) {
+		names, errs := collectTestDir("testdata/discovery", discoveryOptions{recursive: true})
+		assert.Equal(t, []string{"alpha-tests", "beta-tests", "gamma-tests"}, names)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("skips excluded directories", func(t *testing.T) {
+		opts := discoveryOptions{recursive: true, exclude: []string{"legacy"}}
+		names, errs := collectTestDir("testdata/discovery", opts)
+		assert.Equal(t, []string{"alpha-tests", "beta-tests"}, names)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("reads only included directories", func(t *testing.T) {
+		opts := discoveryOptions{recursive: true, include: []string{"**/beta", "alpha"}}
+		names, errs := collectTestDir("testdata/discovery", opts)
+		assert.Equal(t, []string{"alpha-tests", "beta-tests"}, names)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("reports unreadable directories", func(t *testing.T) {
+		_, errs := collectTestDir(filepath.Join("testdata", "does-not-exist"), discoveryOptions{})
+		assert.Equal(t, []string{""}, errs)
+	})
+}
+
+func Test_matchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "aws-*", name: "aws-sdk-v3", expected: true},
+		{pattern: "aws-*", name: "group/aws-sdk-v3", expected: false},
+		{pattern: "**/aws-*", name: "aws-sdk-v3", expected: true},
+		{pattern: "**/aws-*", name: "group/aws-sdk-v3", expected: true},
+		{pattern: "group/**", name: "group/a/b", expected: true},
+		{pattern: "group/**", name: "other/a", expected: false},
+		{pattern: "group/*/c", name: "group/a/b", expected: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pattern+" "+tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, matchGlob(tc.pattern, tc.name))
+		})
+	}
+}
+
+func Test_splitList(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, splitList(" a, ,b,"))
+	assert.Equal(t, []string{}, splitList(""))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// missingTestFiles returns the files listed by a test block that do not
+// exist. The files are resolved relative to the directory that contains the
+// versioned test `package.json`.
+func missingTestFiles(pkgDir string, test TestDescription) []string {
+	results := make([]string, 0)
+	for _, file := range test.Files {
+		_, err := os.Stat(filepath.Join(pkgDir, file))
+		if err != nil {
+			results = append(results, file)
+		}
+	}
+	return results
+}
+
+// hasExistingTestFile determines if any test block that depends on the
+// target lists at least one test file that exists.
+func hasExistingTestFile(pkgDir string, target Target, tests []TestDescription) bool {
+	return slices.ContainsFunc(tests, func(test TestDescription) bool {
+		if dependsOn(test, target.Name) == false {
+			return false
+		}
+		return len(missingTestFiles(pkgDir, test)) < len(test.Files)
+	})
+}
+
+// excludeTestsWithMissingFiles removes every test block that lists a test
+// file that does not exist from the package. Such blocks are skipped by the
+// versioned test runner, and must not be used as evidence of support. The
+// removed blocks are returned.
+func excludeTestsWithMissingFiles(pkgDir string, pkg *VersionedTestPackageJson) []TestDescription {
+	removed := make([]TestDescription, 0)
+	pkg.Tests = slices.DeleteFunc(pkg.Tests, func(test TestDescription) bool {
+		if len(missingTestFiles(pkgDir, test)) == 0 {
+			return false
+		}
+		removed = append(removed, test)
+		return true
+	})
+	return removed
+}
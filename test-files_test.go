@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestFiles creates empty test files within a temporary directory, and
+// returns the directory.
+func writeTestFiles(t *testing.T, files ...string) string {
+	dir := t.TempDir()
+	for _, file := range files {
+		err := os.WriteFile(filepath.Join(dir, file), []byte{}, 0o644)
+		require.Nil(t, err)
+	}
+	return dir
+}
+
+func Test_missingTestFiles(t *testing.T) {
+	dir := writeTestFiles(t, "foo.test.js")
+
+	test := TestDescription{Files: FilesBlock{"foo.test.js", "bar.test.js"}}
+	assert.Equal(t, []string{"bar.test.js"}, missingTestFiles(dir, test))
+
+	test = TestDescription{Files: FilesBlock{"foo.test.js"}}
+	assert.Empty(t, missingTestFiles(dir, test))
+}
+
+func Test_hasExistingTestFile(t *testing.T) {
+	dir := writeTestFiles(t, "foo.test.js")
+	target := Target{Name: "foo"}
+
+	tests := []TestDescription{
+		{Dependencies: DependenciesBlock{"foo": {Versions: ">=1"}}, Files: FilesBlock{"missing.test.js"}},
+		{Dependencies: DependenciesBlock{"bar": {Versions: ">=1"}}, Files: FilesBlock{"foo.test.js"}},
+	}
+	assert.Equal(t, false, hasExistingTestFile(dir, target, tests))
+
+	tests = append(tests, TestDescription{
+		Dependencies: DependenciesBlock{"foo": {Versions: ">=2"}},
+		Files:        FilesBlock{"missing.test.js", "foo.test.js"},
+	})
+	assert.Equal(t, true, hasExistingTestFile(dir, target, tests))
+}
+
+func Test_excludeTestsWithMissingFiles(t *testing.T) {
+	dir := writeTestFiles(t, "foo.test.js")
+	pkg := &VersionedTestPackageJson{
+		Name:    "foo-tests",
+		Targets: []Target{{Name: "foo"}},
+		Tests: []TestDescription{
+			{
+				Supported:    true,
+				Dependencies: DependenciesBlock{"foo": {Versions: ">=1.0.0"}},
+				Files:        FilesBlock{"foo.test.js", "legacy.test.js"},
+			},
+			{
+				Supported:    true,
+				Dependencies: DependenciesBlock{"foo": {Versions: ">=2.0.0"}},
+				Files:        FilesBlock{"foo.test.js"},
+			},
+		},
+	}
+
+	removed := excludeTestsWithMissingFiles(dir, pkg)
+	require.Len(t, removed, 1)
+	assert.Equal(t, ">=1.0.0", removed[0].Dependencies["foo"].Versions)
+	require.Len(t, pkg.Tests, 1)
+	assert.Equal(t, ">=2.0.0", pkg.Tests[0].Dependencies["foo"].Versions)
+
+	infos, err := parsePackage(pkg)
+	require.Nil(t, err)
+	assert.Equal(t, "2.0.0", infos[0].MinVersion)
+}
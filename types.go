@@ -12,17 +12,36 @@ type nrRepo struct {
 	repoDir    string
 	url        string
 	branch     string
-	testPath   string
+	testPaths  []string
+}
+
+// versionedTestDir describes a directory of versioned tests within a
+// repository.
+type versionedTestDir struct {
+	// repo identifies the repository, i.e. its URL or local directory.
+	repo string
+
+	// root is the directory on the file system that contains the repository.
+	root string
+
+	// path is the directory on the file system that contains the versioned
+	// tests.
+	path string
 }
 
 type dirIterChan struct {
 	name string
+	path string
 	pkg  *VersionedTestPackageJson
 	err  error
 }
 
 // CloneRepoResult represents the status of Git repository clone operation.
 type CloneRepoResult struct {
+	// Repo identifies the repository that was cloned, i.e. its URL or local
+	// directory.
+	Repo string
+
 	// IsMainRepo indicates if the clone represents the mainline Node.js Agent
 	// repository. The mainline repo includes extra configuration that is needed
 	// by the tool. Since cloning happens concurrently, we don't know which
@@ -33,9 +52,9 @@ type CloneRepoResult struct {
 	// repository.
 	Directory string
 
-	// TestDirectory is a string relative to Directory that contains the
+	// TestDirectories are paths relative to Directory that contain the
 	// versioned tests for the repository.
-	TestDirectory string
+	TestDirectories []string
 
 	// Remove indicates if the Directory should be removed after all data
 	// processing has completed.
@@ -49,12 +68,92 @@ type CloneRepoResult struct {
 // ReleaseData represents a row of information about a package. Specifically,
 // it's the final computed information to be rendered into documents.
 type ReleaseData struct {
-	Name                       string
-	MinSupportedVersion        string
-	MinSupportedVersionRelease string
-	LatestVersion              string
-	LatestVersionRelease       string
-	MinAgentVersion            string
+	Name                       string `json:"name"`
+	MinSupportedVersion        string `json:"minSupportedVersion"`
+	MinSupportedVersionRelease string `json:"minSupportedVersionRelease"`
+	LatestVersion              string `json:"latestVersion"`
+	LatestVersionRelease       string `json:"latestVersionRelease"`
+	MinAgentVersion            string `json:"minAgentVersion"`
+	MinNodeVersion             string `json:"minNodeVersion"`
+
+	// TestedVersions is the set of published versions that the versioned
+	// test runner will install and test against.
+	TestedVersions []string `json:"testedVersions"`
+
+	// UntestedVersions is the set of published versions that fall within
+	// the supported ranges but are not selected by the versioned test runner.
+	UntestedVersions []string `json:"untestedVersions"`
+
+	// NodeEngines lists the Node.js engine ranges under which each supported
+	// range of the package is tested.
+	NodeEngines []RangeNodeEngine `json:"nodeEngines"`
+
+	// KnownIncompatible lists the ranges of the package that are explicitly
+	// tested as not supported.
+	KnownIncompatible []IncompatibleRange `json:"knownIncompatible,omitempty"`
+
+	// Tests lists the versioned test blocks that cover the package.
+	Tests []TargetTest `json:"tests"`
+
+	// Warnings describes problems found while computing the row. Rows with
+	// warnings are annotated in the rendered table.
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Sources lists every versioned test file that contributed to the row.
+	Sources []ReleaseSource `json:"sources,omitempty"`
+
+	// Conflicts describes the fields on which the sources of the row
+	// disagree.
+	Conflicts []MergeConflict `json:"conflicts,omitempty"`
+}
+
+// ReleaseSource identifies a versioned test `package.json` that contributed
+// to a [ReleaseData] row, along with the values it supplied.
+type ReleaseSource struct {
+	Repo                string `json:"repo"`
+	File                string `json:"file"`
+	MinSupportedVersion string `json:"minSupportedVersion"`
+	MinAgentVersion     string `json:"minAgentVersion"`
+
+	// Trace records how the source derived its minimum supported version. It
+	// is only recorded when explaining a module.
+	Trace *DerivationTrace `json:"-"`
+}
+
+func (rs ReleaseSource) String() string {
+	if rs.Repo == "" {
+		return rs.File
+	}
+	return rs.Repo + ":" + rs.File
+}
+
+// MergeConflict describes a field of a [ReleaseData] row for which the
+// sources of the row supply different values.
+type MergeConflict struct {
+	Field  string          `json:"field"`
+	Values []ConflictValue `json:"values"`
+}
+
+// ConflictValue is one of the values supplied for a conflicting field, along
+// with the sources that supplied it.
+type ConflictValue struct {
+	Value   string          `json:"value"`
+	Sources []ReleaseSource `json:"sources"`
+}
+
+// RangeNodeEngine pairs a tested range of a package with the Node.js engine
+// range the tests are run under. An empty Node value indicates the tests
+// are not constrained to any Node.js versions.
+type RangeNodeEngine struct {
+	Versions string `json:"versions"`
+	Node     string `json:"node"`
+}
+
+// IncompatibleRange represents a range of a package that the versioned tests
+// declare as not supported, along with the reason given for it.
+type IncompatibleRange struct {
+	Versions string `json:"versions"`
+	Comment  string `json:"comment"`
 }
 
 type Target struct {
@@ -68,6 +167,7 @@ type VersionedTestPackageJson struct {
 	Targets []Target          `json:"targets"`
 	Version string            `json:"version"`
 	Private bool              `json:"private"`
+	Engines EnginesBlock      `json:"engines"`
 	Tests   []TestDescription `json:"tests"`
 }
 